@@ -0,0 +1,261 @@
+package bot
+
+import (
+	"errors"
+	"log"
+	"math"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+	"golang.org/x/exp/slices"
+)
+
+// Allocation is how attribute points are spent.
+type Allocation string
+
+const (
+	// AllocateProfile splits all the points by the profile.
+	AllocateProfile Allocation = "profile"
+	// AllocateUnlock first spends the points needed to pay for equipped skills
+	// and to meet requirements of items we could get, the rest is split by the profile.
+	AllocateUnlock Allocation = "unlock"
+	// AllocateMarginal spends the points one by one on the attribute improving marginalScore the most.
+	// Points nothing improves are split by the profile.
+	AllocateMarginal Allocation = "marginal"
+)
+
+// skillPointsMargin is kept unspent so rounding never makes us assign more points than we have.
+const skillPointsMargin = 0.1
+
+func (d Default) spendAttributePoints(state *swagger.DungeonsandtrollsGameState) *swagger.DungeonsandtrollsAttributes {
+	points := state.Character.SkillPoints - skillPointsMargin
+
+	allocated := &swagger.DungeonsandtrollsAttributes{}
+	if d.Allocation == AllocateUnlock {
+		allocated = allocateUnlocks(state, d.damageTypes(state), points)
+		points -= attributeSum(allocated)
+	}
+	if d.Allocation == AllocateMarginal {
+		allocated = allocateMarginal(state, points)
+		points -= attributeSum(allocated)
+	}
+
+	return addAttributes(allocated, allocateByProfile(state, d.Profile, points))
+}
+
+// allocateByProfile splits the points by the weights of the profile at the deepest level reached.
+func allocateByProfile(state *swagger.DungeonsandtrollsGameState, profile Profile, points float32) *swagger.DungeonsandtrollsAttributes {
+	weights := profile.weights(state.MaxLevel)
+	return scaleAttributes(weights, points/attributeSum(weights))
+}
+
+// unlock is an equipped skill or an item that needs more attribute points to be used.
+type unlock struct {
+	name         string
+	equipped     bool
+	requirements *swagger.DungeonsandtrollsAttributes
+	// have are the attributes compared against the requirements.
+	have  *swagger.DungeonsandtrollsAttributes
+	value float32
+}
+
+// allocateUnlocks spends the points on the missing attributes of unlocks that fit within the points,
+// equipped skills first, then items by how much they improve the loadout.
+func allocateUnlocks(state *swagger.DungeonsandtrollsGameState, types []swagger.DungeonsandtrollsDamageType, points float32) *swagger.DungeonsandtrollsAttributes {
+	unlocks := findUnlocks(state, types)
+	slices.SortStableFunc(unlocks, func(a, b unlock) int {
+		if a.equipped != b.equipped {
+			if a.equipped {
+				return -1
+			}
+			return 1
+		}
+		if a.value > b.value {
+			return -1
+		}
+		if a.value < b.value {
+			return 1
+		}
+		return 0
+	})
+
+	allocated := &swagger.DungeonsandtrollsAttributes{}
+	for _, u := range unlocks {
+		missing := missingAmounts(addAttributes(u.have, allocated), u.requirements)
+		cost := attributeSum(missing)
+		if cost == 0 || cost > points {
+			continue
+		}
+		log.Printf("Unlocking %s for %.2f points\n", u.name, cost)
+		allocated = addAttributes(allocated, missing)
+		points -= cost
+	}
+	return allocated
+}
+
+// findUnlocks lists equipped skills we can't pay for even at full resources,
+// and shop items we can afford and items lying on the level whose requirements we don't meet.
+func findUnlocks(state *swagger.DungeonsandtrollsGameState, types []swagger.DungeonsandtrollsDamageType) []unlock {
+	var unlocks []unlock
+
+	maxAttrs := state.Character.MaxAttributes
+	if maxAttrs == nil {
+		maxAttrs = state.Character.Attributes
+	}
+	for _, item := range state.Character.Equip {
+		for _, skill := range item.Skills {
+			if skill.Cost == nil || (skill.Flags != nil && skill.Flags.Passive) || haveRequiredAttirbutes(maxAttrs, skill.Cost) {
+				continue
+			}
+			value := float32(1)
+			if isAttackSkill(&skill) {
+				value += skillDamage(state.Character.Attributes, &skill, nil)
+			}
+			unlocks = append(unlocks, unlock{
+				name:         skill.Name,
+				equipped:     true,
+				requirements: skill.Cost,
+				have:         maxAttrs,
+				value:        value,
+			})
+		}
+	}
+
+	owned := loadout(state.Character.Equip)
+	base := baseAttributes(state)
+	score := shopScore(types)
+	current := score(owned, owned.attributes(base))
+
+	candidates := []swagger.DungeonsandtrollsItem{}
+	for _, item := range state.ShopItems {
+		if int(item.Price) <= int(state.Character.Money) {
+			candidates = append(candidates, item)
+		}
+	}
+	for _, level := range state.Map_.Levels {
+		if level.Level != state.CurrentLevel {
+			continue
+		}
+		for _, object := range level.Objects {
+			candidates = append(candidates, object.Items...)
+		}
+	}
+
+	for _, item := range candidates {
+		if item.Slot == nil || item.Requirements == nil {
+			continue
+		}
+		items := owned.replace(item)
+		attrs := items.attributes(base)
+		if haveRequiredAttirbutes(attrs, item.Requirements) {
+			continue
+		}
+		if err := items.validate(base, math.MaxInt); err != nil && !errors.Is(err, errRequirementsUnmet) {
+			continue
+		}
+		if gain := score(items, attrs) - current; gain > 0 {
+			unlocks = append(unlocks, unlock{
+				name:         item.Name,
+				requirements: item.Requirements,
+				have:         attrs,
+				value:        gain,
+			})
+		}
+	}
+
+	return unlocks
+}
+
+const (
+	marginalDamageWeight  = 1
+	marginalRangeWeight   = 2
+	marginalSustainWeight = 0.5
+	marginalLifeWeight    = 0.1
+	marginalThreatWeight  = 1
+)
+
+// attributeUnits are single points of each attribute that can be allocated.
+var attributeUnits = []swagger.DungeonsandtrollsAttributes{
+	{Strength: 1}, {Dexterity: 1}, {Intelligence: 1}, {Willpower: 1}, {Constitution: 1},
+	{SlashResist: 1}, {PierceResist: 1}, {FireResist: 1}, {PoisonResist: 1}, {ElectricResist: 1},
+	{Life: 1}, {Stamina: 1}, {Mana: 1},
+}
+
+// allocateMarginal greedily spends the points a point at a time on the attribute with the best marginal gain.
+func allocateMarginal(state *swagger.DungeonsandtrollsGameState, points float32) *swagger.DungeonsandtrollsAttributes {
+	allocated := &swagger.DungeonsandtrollsAttributes{}
+	for points > 0 {
+		step := min(points, 1)
+		current := marginalScore(state, addAttributes(state.Character.Attributes, allocated))
+
+		var best *swagger.DungeonsandtrollsAttributes
+		bestGain := float32(0)
+		for i := range attributeUnits {
+			unit := scaleAttributes(&attributeUnits[i], step)
+			gain := marginalScore(state, addAttributes(state.Character.Attributes, allocated, unit)) - current
+			if gain > bestGain {
+				best, bestGain = unit, gain
+			}
+		}
+		if best == nil {
+			break
+		}
+
+		allocated = addAttributes(allocated, best)
+		points -= step
+	}
+	return allocated
+}
+
+// marginalScore rates the attributes by the damage and range of our best attack, the amount our heal and rest
+// skills restore, our life and the damage the monsters on the level would deal to us.
+func marginalScore(state *swagger.DungeonsandtrollsGameState, attrs *swagger.DungeonsandtrollsAttributes) float32 {
+	damage := float32(0)
+	reach := float32(0)
+	sustain := float32(0)
+	for _, item := range state.Character.Equip {
+		for _, skill := range item.Skills {
+			if isAttackSkill(&skill) {
+				if value := skillDamage(attrs, &skill, nil); value > damage {
+					damage = value
+					reach = float32(skillReach(attrs, &skill))
+				}
+			}
+			if skill.TargetEffects != nil && skill.TargetEffects.Attributes != nil && skill.TargetEffects.Attributes.Life != nil {
+				sustain = max(sustain, calculateAttributesValue(attrs, skill.TargetEffects.Attributes.Life))
+			}
+			if skill.CasterEffects != nil && skill.CasterEffects.Attributes != nil {
+				restore := float32(0)
+				if skill.CasterEffects.Attributes.Stamina != nil {
+					restore += calculateAttributesValue(attrs, skill.CasterEffects.Attributes.Stamina)
+				}
+				if skill.CasterEffects.Attributes.Mana != nil {
+					restore += calculateAttributesValue(attrs, skill.CasterEffects.Attributes.Mana)
+				}
+				sustain = max(sustain, restore)
+			}
+		}
+	}
+
+	threat := float32(0)
+	for _, level := range state.Map_.Levels {
+		if level.Level != state.CurrentLevel {
+			continue
+		}
+		for _, object := range level.Objects {
+			for _, monster := range object.Monsters {
+				if monster.Faction == "neutral" {
+					continue
+				}
+				for _, attack := range monsterAttacks(monster, attrs) {
+					threat += attack.damage
+				}
+			}
+		}
+	}
+
+	return damage*marginalDamageWeight +
+		reach*marginalRangeWeight +
+		sustain*marginalSustainWeight +
+		attrs.Life*marginalLifeWeight -
+		threat*marginalThreatWeight
+}
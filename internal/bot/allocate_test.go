@@ -0,0 +1,116 @@
+package bot
+
+import (
+	"testing"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+)
+
+func TestSpendAttributePoints(t *testing.T) {
+	state := &swagger.DungeonsandtrollsGameState{
+		Character: &swagger.DungeonsandtrollsCharacter{SkillPoints: 6.1},
+	}
+	profile := Profile{Stages: []ProfileStage{{
+		Weights: swagger.DungeonsandtrollsAttributes{Intelligence: 2, Constitution: 1},
+	}}}
+
+	got := *Default{Profile: profile}.spendAttributePoints(state)
+	want := swagger.DungeonsandtrollsAttributes{Intelligence: 4, Constitution: 2}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestSpendAttributePointsUnlock(t *testing.T) {
+	sword := testItem("sword", swagger.MAIN_HAND_DungeonsandtrollsItemType, 100, swagger.DungeonsandtrollsAttributes{}, swagger.DungeonsandtrollsAttributes{Strength: 6})
+	expensive := testItem("expensive", swagger.MAIN_HAND_DungeonsandtrollsItemType, 1000, swagger.DungeonsandtrollsAttributes{}, swagger.DungeonsandtrollsAttributes{Dexterity: 6})
+	slash := swagger.SLASH_DungeonsandtrollsDamageType
+	target := swagger.CHARACTER_SkillTarget
+	sword.Skills = []swagger.DungeonsandtrollsSkill{{
+		Id:           "sword-skill",
+		Target:       &target,
+		Cost:         &swagger.DungeonsandtrollsAttributes{},
+		Range_:       &swagger.DungeonsandtrollsAttributes{Constant: 1},
+		DamageAmount: &swagger.DungeonsandtrollsAttributes{Strength: 1},
+		DamageType:   &slash,
+		Flags:        &swagger.DungeonsandtrollsSkillGenericFlags{},
+	}}
+
+	state := &swagger.DungeonsandtrollsGameState{
+		Map_:      &swagger.DungeonsandtrollsMap{},
+		ShopItems: []swagger.DungeonsandtrollsItem{sword, expensive},
+		Character: &swagger.DungeonsandtrollsCharacter{
+			Attributes:  &swagger.DungeonsandtrollsAttributes{Strength: 5, Dexterity: 5},
+			Money:       200,
+			SkillPoints: 3.1,
+		},
+	}
+	profile := Profile{Stages: []ProfileStage{{
+		Weights: swagger.DungeonsandtrollsAttributes{Constitution: 1},
+	}}}
+
+	got := *Default{Profile: profile, Allocation: AllocateUnlock, DamageTypes: 1}.spendAttributePoints(state)
+	want := swagger.DungeonsandtrollsAttributes{Strength: 1, Constitution: 2}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestSpendAttributePointsMarginal(t *testing.T) {
+	fire := swagger.FIRE_DungeonsandtrollsDamageType
+	slash := swagger.SLASH_DungeonsandtrollsDamageType
+	target := swagger.CHARACTER_SkillTarget
+	staff := testItem("staff", swagger.MAIN_HAND_DungeonsandtrollsItemType, 0, swagger.DungeonsandtrollsAttributes{}, swagger.DungeonsandtrollsAttributes{})
+	staff.Skills = []swagger.DungeonsandtrollsSkill{{
+		Target:       &target,
+		Cost:         &swagger.DungeonsandtrollsAttributes{},
+		Range_:       &swagger.DungeonsandtrollsAttributes{Constant: 3},
+		DamageAmount: &swagger.DungeonsandtrollsAttributes{Intelligence: 1},
+		DamageType:   &fire,
+		Flags:        &swagger.DungeonsandtrollsSkillGenericFlags{},
+	}}
+	claw := testItem("claw", swagger.MAIN_HAND_DungeonsandtrollsItemType, 0, swagger.DungeonsandtrollsAttributes{}, swagger.DungeonsandtrollsAttributes{})
+	claw.Skills = []swagger.DungeonsandtrollsSkill{{
+		Range_:       &swagger.DungeonsandtrollsAttributes{Constant: 1},
+		DamageAmount: &swagger.DungeonsandtrollsAttributes{Constant: 40},
+		DamageType:   &slash,
+	}}
+
+	newState := func(monsters ...swagger.DungeonsandtrollsMonster) *swagger.DungeonsandtrollsGameState {
+		return &swagger.DungeonsandtrollsGameState{
+			CurrentLevel: 1,
+			Map_: &swagger.DungeonsandtrollsMap{Levels: []swagger.DungeonsandtrollsLevel{{
+				Level:   1,
+				Objects: []swagger.DungeonsandtrollsMapObjects{{Monsters: monsters}},
+			}}},
+			Character: &swagger.DungeonsandtrollsCharacter{
+				Attributes:  &swagger.DungeonsandtrollsAttributes{Intelligence: 5},
+				Equip:       []swagger.DungeonsandtrollsItem{staff},
+				SkillPoints: 2.1,
+			},
+		}
+	}
+	d := Default{Allocation: AllocateMarginal}
+
+	if got, want := *d.spendAttributePoints(newState()), (swagger.DungeonsandtrollsAttributes{Intelligence: 2}); !approxAttributes(got, want) {
+		t.Errorf("without monsters: got %+v, want %+v", got, want)
+	}
+
+	troll := swagger.DungeonsandtrollsMonster{
+		Attributes:    &swagger.DungeonsandtrollsAttributes{},
+		EquippedItems: []swagger.DungeonsandtrollsItem{claw},
+	}
+	if got, want := *d.spendAttributePoints(newState(troll)), (swagger.DungeonsandtrollsAttributes{SlashResist: 2}); !approxAttributes(got, want) {
+		t.Errorf("against a hard hitting monster: got %+v, want %+v", got, want)
+	}
+}
+
+func approxAttributes(a, b swagger.DungeonsandtrollsAttributes) bool {
+	av, bv := attributeValues(&a), attributeValues(&b)
+	for i := range av {
+		if av[i]-bv[i] > 0.001 || bv[i]-av[i] > 0.001 {
+			return false
+		}
+	}
+	return true
+}
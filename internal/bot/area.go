@@ -0,0 +1,129 @@
+package bot
+
+import (
+	"math"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+)
+
+// isAreaSkill reports whether the skill deals damage to everything within its radius
+// around a targeted position or the caster.
+func isAreaSkill(skill *swagger.DungeonsandtrollsSkill) bool {
+	if skill.Target == nil || skill.DamageAmount == nil {
+		return false
+	}
+	switch *skill.Target {
+	case swagger.POSITION_SkillTarget:
+		return true
+	case swagger.NONE_SkillTarget:
+		return skill.Radius != nil
+	}
+	return false
+}
+
+func skillRadius(attrs *swagger.DungeonsandtrollsAttributes, skill *swagger.DungeonsandtrollsSkill) int {
+	if skill.Radius == nil {
+		return 0
+	}
+	return int(math.Trunc(float64(calculateAttributesValue(attrs, skill.Radius))))
+}
+
+// skillReach is how far from the caster a monster can be and still get hit by the skill.
+func skillReach(attrs *swagger.DungeonsandtrollsAttributes, skill *swagger.DungeonsandtrollsSkill) int {
+	reach := 0
+	if skill.Range_ != nil && (skill.Target == nil || *skill.Target != swagger.NONE_SkillTarget) {
+		reach = int(math.Trunc(float64(calculateAttributesValue(attrs, skill.Range_))))
+	}
+	if isAreaSkill(skill) {
+		reach += skillRadius(attrs, skill)
+	}
+	return reach
+}
+
+// areaHit is where to cast an area skill and what it hits.
+type areaHit struct {
+	position swagger.DungeonsandtrollsPosition
+	monsters int
+	damage   float32
+}
+
+// bestArea returns the position to cast the area skill at dealing the most damage to monsters
+// without hitting ourselves or other players. If target is given, it has to be hit.
+// It returns nil if there is no such position.
+func bestArea(state *swagger.DungeonsandtrollsGameState, skill *swagger.DungeonsandtrollsSkill, target *target) *areaHit {
+	attrs := state.Character.Attributes
+	radius := skillRadius(attrs, skill)
+
+	centers := []swagger.DungeonsandtrollsPosition{*state.CurrentPosition}
+	var monsters, allies []*swagger.DungeonsandtrollsMapObjects
+	for _, level := range state.Map_.Levels {
+		if level.Level != state.CurrentLevel {
+			continue
+		}
+
+		if *skill.Target == swagger.POSITION_SkillTarget {
+			rang := 0
+			if skill.Range_ != nil {
+				rang = int(math.Trunc(float64(calculateAttributesValue(attrs, skill.Range_))))
+			}
+			for _, pm := range level.PlayerMap {
+				if pm.LineOfSight && int(pm.Distance) <= rang {
+					centers = append(centers, *pm.Position)
+				}
+			}
+		}
+
+		for i := range level.Objects {
+			object := &level.Objects[i]
+			if len(object.Monsters) > 0 {
+				monsters = append(monsters, object)
+			}
+			for _, player := range object.Players {
+				if player.Id != state.Character.Id {
+					allies = append(allies, object)
+					break
+				}
+			}
+		}
+	}
+
+	var best *areaHit
+	for _, center := range centers {
+		// A POSITION target area hits the caster too. A NONE target one is centered on the caster,
+		// who is not hit by it.
+		if *skill.Target == swagger.POSITION_SkillTarget && distance(center, *state.CurrentPosition) <= radius {
+			continue
+		}
+		if target != nil && distance(center, *target.object.Position) > radius {
+			continue
+		}
+		hitsAlly := false
+		for _, ally := range allies {
+			if distance(center, *ally.Position) <= radius {
+				hitsAlly = true
+				break
+			}
+		}
+		if hitsAlly {
+			continue
+		}
+
+		hit := areaHit{position: center}
+		for _, object := range monsters {
+			if distance(center, *object.Position) > radius {
+				continue
+			}
+			for _, monster := range object.Monsters {
+				if monster.Faction == "neutral" {
+					continue
+				}
+				hit.monsters++
+				hit.damage += skillDamage(attrs, skill, monster.Attributes)
+			}
+		}
+		if hit.monsters > 0 && (best == nil || hit.damage > best.damage) {
+			best = &hit
+		}
+	}
+	return best
+}
@@ -0,0 +1,93 @@
+package bot
+
+import (
+	"testing"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+)
+
+func TestBestArea(t *testing.T) {
+	electric := swagger.ELECTRIC_DungeonsandtrollsDamageType
+	position := swagger.POSITION_SkillTarget
+	skill := &swagger.DungeonsandtrollsSkill{
+		Target:       &position,
+		Range_:       &swagger.DungeonsandtrollsAttributes{Constant: 6},
+		Radius:       &swagger.DungeonsandtrollsAttributes{Constant: 1},
+		DamageAmount: &swagger.DungeonsandtrollsAttributes{Constant: 10},
+		DamageType:   &electric,
+	}
+	pos := func(x, y int32) *swagger.DungeonsandtrollsPosition {
+		return &swagger.DungeonsandtrollsPosition{PositionX: x, PositionY: y}
+	}
+	monster := []swagger.DungeonsandtrollsMonster{{Attributes: &swagger.DungeonsandtrollsAttributes{}}}
+
+	newState := func(objects ...swagger.DungeonsandtrollsMapObjects) *swagger.DungeonsandtrollsGameState {
+		var playerMap []swagger.DungeonsandtrollsPlayerSpecificMap
+		for x := int32(0); x < 8; x++ {
+			for y := int32(0); y < 8; y++ {
+				playerMap = append(playerMap, swagger.DungeonsandtrollsPlayerSpecificMap{
+					Position:    pos(x, y),
+					Distance:    x + y,
+					LineOfSight: true,
+				})
+			}
+		}
+		return &swagger.DungeonsandtrollsGameState{
+			CurrentPosition: pos(0, 0),
+			Character: &swagger.DungeonsandtrollsCharacter{
+				Id:         "me",
+				Attributes: &swagger.DungeonsandtrollsAttributes{},
+			},
+			Map_: &swagger.DungeonsandtrollsMap{Levels: []swagger.DungeonsandtrollsLevel{{
+				Objects:   objects,
+				PlayerMap: playerMap,
+			}}},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		state    *swagger.DungeonsandtrollsGameState
+		want     *swagger.DungeonsandtrollsPosition // nil when any position hitting the monsters will do
+		monsters int
+	}{
+		{
+			"between two monsters",
+			newState(
+				swagger.DungeonsandtrollsMapObjects{Position: pos(3, 0), Monsters: monster},
+				swagger.DungeonsandtrollsMapObjects{Position: pos(3, 2), Monsters: monster},
+			),
+			pos(3, 1), 2,
+		},
+		{
+			"sparing an ally",
+			newState(
+				swagger.DungeonsandtrollsMapObjects{Position: pos(3, 0), Monsters: monster},
+				swagger.DungeonsandtrollsMapObjects{Position: pos(3, 2), Monsters: monster},
+				swagger.DungeonsandtrollsMapObjects{Position: pos(4, 1), Players: []swagger.DungeonsandtrollsCharacter{{Id: "ally"}}},
+			),
+			nil, 1,
+		},
+		{
+			"hitting ourselves or an ally",
+			newState(
+				swagger.DungeonsandtrollsMapObjects{Position: pos(0, 1), Monsters: monster},
+				swagger.DungeonsandtrollsMapObjects{Position: pos(1, 2), Players: []swagger.DungeonsandtrollsCharacter{{Id: "ally"}}},
+			),
+			nil, 0,
+		},
+	}
+	for _, tt := range tests {
+		area := bestArea(tt.state, skill, nil)
+		switch {
+		case area == nil:
+			if tt.monsters > 0 {
+				t.Errorf("%s: got no area, want one hitting %d", tt.name, tt.monsters)
+			}
+		case area.monsters != tt.monsters:
+			t.Errorf("%s: got %+v hitting %d, want hitting %d", tt.name, area.position, area.monsters, tt.monsters)
+		case tt.want != nil && area.position != *tt.want:
+			t.Errorf("%s: got %+v, want %+v", tt.name, area.position, *tt.want)
+		}
+	}
+}
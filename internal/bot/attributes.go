@@ -0,0 +1,197 @@
+package bot
+
+import (
+	"fmt"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+)
+
+func calculateAttributesValue(myAttrs *swagger.DungeonsandtrollsAttributes, attrs *swagger.DungeonsandtrollsAttributes) float32 {
+	var value float32
+	value += myAttrs.Strength * attrs.Strength
+	value += myAttrs.Dexterity * attrs.Dexterity
+	value += myAttrs.Intelligence * attrs.Intelligence
+	value += myAttrs.Willpower * attrs.Willpower
+	value += myAttrs.Constitution * attrs.Constitution
+	value += myAttrs.SlashResist * attrs.SlashResist
+	value += myAttrs.PierceResist * attrs.PierceResist
+	value += myAttrs.FireResist * attrs.FireResist
+	value += myAttrs.PoisonResist * attrs.PoisonResist
+	value += myAttrs.ElectricResist * attrs.ElectricResist
+	value += myAttrs.Life * attrs.Life
+	value += myAttrs.Stamina * attrs.Stamina
+	value += myAttrs.Mana * attrs.Mana
+	value += attrs.Constant
+	return value
+}
+
+func haveRequiredAttirbutes(myAttrs *swagger.DungeonsandtrollsAttributes, requirements *swagger.DungeonsandtrollsAttributes) bool {
+	return myAttrs.Strength >= requirements.Strength &&
+		myAttrs.Dexterity >= requirements.Dexterity &&
+		myAttrs.Intelligence >= requirements.Intelligence &&
+		myAttrs.Willpower >= requirements.Willpower &&
+		myAttrs.Constitution >= requirements.Constitution &&
+		myAttrs.SlashResist >= requirements.SlashResist &&
+		myAttrs.PierceResist >= requirements.PierceResist &&
+		myAttrs.FireResist >= requirements.FireResist &&
+		myAttrs.PoisonResist >= requirements.PoisonResist &&
+		myAttrs.ElectricResist >= requirements.ElectricResist &&
+		myAttrs.Life >= requirements.Life &&
+		myAttrs.Stamina >= requirements.Stamina &&
+		myAttrs.Mana >= requirements.Mana
+}
+
+func addAttributes(attrs ...*swagger.DungeonsandtrollsAttributes) *swagger.DungeonsandtrollsAttributes {
+	if len(attrs) == 0 {
+		return nil
+	}
+	if len(attrs) == 1 {
+		return attrs[0]
+	}
+
+	firstAttrs := attrs[0]
+	otherAttrs := addAttributes(attrs[1:]...)
+
+	return &swagger.DungeonsandtrollsAttributes{
+		Strength:       firstAttrs.Strength + otherAttrs.Strength,
+		Dexterity:      firstAttrs.Dexterity + otherAttrs.Dexterity,
+		Intelligence:   firstAttrs.Intelligence + otherAttrs.Intelligence,
+		Willpower:      firstAttrs.Willpower + otherAttrs.Willpower,
+		Constitution:   firstAttrs.Constitution + otherAttrs.Constitution,
+		SlashResist:    firstAttrs.SlashResist + otherAttrs.SlashResist,
+		PierceResist:   firstAttrs.PierceResist + otherAttrs.PierceResist,
+		FireResist:     firstAttrs.FireResist + otherAttrs.FireResist,
+		PoisonResist:   firstAttrs.PoisonResist + otherAttrs.PoisonResist,
+		ElectricResist: firstAttrs.ElectricResist + otherAttrs.ElectricResist,
+		Life:           firstAttrs.Life + otherAttrs.Life,
+		Stamina:        firstAttrs.Stamina + otherAttrs.Stamina,
+		Mana:           firstAttrs.Mana + otherAttrs.Mana,
+		Constant:       firstAttrs.Constant + otherAttrs.Constant,
+	}
+}
+
+// missingAttributes lists the names of the attributes that don't meet the requirements.
+func missingAttributes(myAttrs *swagger.DungeonsandtrollsAttributes, requirements *swagger.DungeonsandtrollsAttributes) []string {
+	var missing []string
+	check := func(name string, have, need float32) {
+		if have < need {
+			missing = append(missing, fmt.Sprintf("%s %g < %g", name, have, need))
+		}
+	}
+	check("strength", myAttrs.Strength, requirements.Strength)
+	check("dexterity", myAttrs.Dexterity, requirements.Dexterity)
+	check("intelligence", myAttrs.Intelligence, requirements.Intelligence)
+	check("willpower", myAttrs.Willpower, requirements.Willpower)
+	check("constitution", myAttrs.Constitution, requirements.Constitution)
+	check("slash resist", myAttrs.SlashResist, requirements.SlashResist)
+	check("pierce resist", myAttrs.PierceResist, requirements.PierceResist)
+	check("fire resist", myAttrs.FireResist, requirements.FireResist)
+	check("poison resist", myAttrs.PoisonResist, requirements.PoisonResist)
+	check("electric resist", myAttrs.ElectricResist, requirements.ElectricResist)
+	check("life", myAttrs.Life, requirements.Life)
+	check("stamina", myAttrs.Stamina, requirements.Stamina)
+	check("mana", myAttrs.Mana, requirements.Mana)
+	return missing
+}
+
+func subtractAttributes(myAttrs *swagger.DungeonsandtrollsAttributes, attrs *swagger.DungeonsandtrollsAttributes) *swagger.DungeonsandtrollsAttributes {
+	return &swagger.DungeonsandtrollsAttributes{
+		Strength:       myAttrs.Strength - attrs.Strength,
+		Dexterity:      myAttrs.Dexterity - attrs.Dexterity,
+		Intelligence:   myAttrs.Intelligence - attrs.Intelligence,
+		Willpower:      myAttrs.Willpower - attrs.Willpower,
+		Constitution:   myAttrs.Constitution - attrs.Constitution,
+		SlashResist:    myAttrs.SlashResist - attrs.SlashResist,
+		PierceResist:   myAttrs.PierceResist - attrs.PierceResist,
+		FireResist:     myAttrs.FireResist - attrs.FireResist,
+		PoisonResist:   myAttrs.PoisonResist - attrs.PoisonResist,
+		ElectricResist: myAttrs.ElectricResist - attrs.ElectricResist,
+		Life:           myAttrs.Life - attrs.Life,
+		Stamina:        myAttrs.Stamina - attrs.Stamina,
+		Mana:           myAttrs.Mana - attrs.Mana,
+		Constant:       myAttrs.Constant - attrs.Constant,
+	}
+}
+
+// attributeValues lists all the attributes except the constant.
+func attributeValues(attrs *swagger.DungeonsandtrollsAttributes) []float32 {
+	return []float32{
+		attrs.Strength, attrs.Dexterity, attrs.Intelligence, attrs.Willpower, attrs.Constitution,
+		attrs.SlashResist, attrs.PierceResist, attrs.FireResist, attrs.PoisonResist, attrs.ElectricResist,
+		attrs.Life, attrs.Stamina, attrs.Mana,
+	}
+}
+
+func scaleAttributes(attrs *swagger.DungeonsandtrollsAttributes, factor float32) *swagger.DungeonsandtrollsAttributes {
+	return &swagger.DungeonsandtrollsAttributes{
+		Strength:       attrs.Strength * factor,
+		Dexterity:      attrs.Dexterity * factor,
+		Intelligence:   attrs.Intelligence * factor,
+		Willpower:      attrs.Willpower * factor,
+		Constitution:   attrs.Constitution * factor,
+		SlashResist:    attrs.SlashResist * factor,
+		PierceResist:   attrs.PierceResist * factor,
+		FireResist:     attrs.FireResist * factor,
+		PoisonResist:   attrs.PoisonResist * factor,
+		ElectricResist: attrs.ElectricResist * factor,
+		Life:           attrs.Life * factor,
+		Stamina:        attrs.Stamina * factor,
+		Mana:           attrs.Mana * factor,
+		Constant:       attrs.Constant * factor,
+	}
+}
+
+func attributeSum(attrs *swagger.DungeonsandtrollsAttributes) float32 {
+	total := float32(0)
+	for _, value := range attributeValues(attrs) {
+		total += value
+	}
+	return total
+}
+
+// missingAmounts returns how much is missing of each attribute to meet the requirements.
+func missingAmounts(myAttrs *swagger.DungeonsandtrollsAttributes, requirements *swagger.DungeonsandtrollsAttributes) *swagger.DungeonsandtrollsAttributes {
+	return &swagger.DungeonsandtrollsAttributes{
+		Strength:       max(requirements.Strength-myAttrs.Strength, 0),
+		Dexterity:      max(requirements.Dexterity-myAttrs.Dexterity, 0),
+		Intelligence:   max(requirements.Intelligence-myAttrs.Intelligence, 0),
+		Willpower:      max(requirements.Willpower-myAttrs.Willpower, 0),
+		Constitution:   max(requirements.Constitution-myAttrs.Constitution, 0),
+		SlashResist:    max(requirements.SlashResist-myAttrs.SlashResist, 0),
+		PierceResist:   max(requirements.PierceResist-myAttrs.PierceResist, 0),
+		FireResist:     max(requirements.FireResist-myAttrs.FireResist, 0),
+		PoisonResist:   max(requirements.PoisonResist-myAttrs.PoisonResist, 0),
+		ElectricResist: max(requirements.ElectricResist-myAttrs.ElectricResist, 0),
+		Life:           max(requirements.Life-myAttrs.Life, 0),
+		Stamina:        max(requirements.Stamina-myAttrs.Stamina, 0),
+		Mana:           max(requirements.Mana-myAttrs.Mana, 0),
+	}
+}
+
+// applySkillAttributes returns attrs changed by the skill effect attributes scaled by the caster's attributes.
+func applySkillAttributes(attrs *swagger.DungeonsandtrollsAttributes, effects *swagger.DungeonsandtrollsSkillAttributes, caster *swagger.DungeonsandtrollsAttributes) *swagger.DungeonsandtrollsAttributes {
+	res := *attrs
+	if effects == nil {
+		return &res
+	}
+	apply := func(value *float32, effect *swagger.DungeonsandtrollsAttributes) {
+		if effect != nil {
+			*value += calculateAttributesValue(caster, effect)
+		}
+	}
+	apply(&res.Strength, effects.Strength)
+	apply(&res.Dexterity, effects.Dexterity)
+	apply(&res.Intelligence, effects.Intelligence)
+	apply(&res.Willpower, effects.Willpower)
+	apply(&res.Constitution, effects.Constitution)
+	apply(&res.SlashResist, effects.SlashResist)
+	apply(&res.PierceResist, effects.PierceResist)
+	apply(&res.FireResist, effects.FireResist)
+	apply(&res.PoisonResist, effects.PoisonResist)
+	apply(&res.ElectricResist, effects.ElectricResist)
+	apply(&res.Life, effects.Life)
+	apply(&res.Stamina, effects.Stamina)
+	apply(&res.Mana, effects.Mana)
+	apply(&res.Constant, effects.Constant)
+	return &res
+}
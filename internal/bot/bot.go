@@ -0,0 +1,149 @@
+// Package bot decides what the character does in each tick. Default and its optional helpers
+// remember what they have seen across ticks and are not safe for concurrent use.
+package bot
+
+import (
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+	"github.com/liennie/gdt/internal/grid"
+)
+
+// Strategy decides what to do in a single tick.
+// A nil command means there is nothing to do this tick.
+type Strategy interface {
+	Decide(state swagger.DungeonsandtrollsGameState) *swagger.DungeonsandtrollsCommandsBatch
+}
+
+// Respawner is a Strategy that may decide to respawn the character, which no command can do.
+type Respawner interface {
+	Strategy
+	// Respawning reports whether the last decision was to respawn.
+	Respawning() bool
+}
+
+type StrategyFunc func(state swagger.DungeonsandtrollsGameState) *swagger.DungeonsandtrollsCommandsBatch
+
+func (f StrategyFunc) Decide(state swagger.DungeonsandtrollsGameState) *swagger.DungeonsandtrollsCommandsBatch {
+	return f(state)
+}
+
+// Default is the strategy the bot plays with.
+type Default struct {
+	// RiskTolerance is the damage we are willing to take to save a single step when routing.
+	// Zero means DefaultRiskTolerance.
+	RiskTolerance float64
+
+	// DamageTypes is how many damage types to build the loadout around, picked by what the shop offers,
+	// what we have equipped and what the monsters resist. Zero means a single one.
+	DamageTypes int
+
+	// Profile tells how to spend attribute points. The zero value means the DefaultProfile.
+	Profile Profile
+
+	// Allocation is how attribute points are spent. The zero value means AllocateProfile.
+	Allocation Allocation
+
+	// Skills tracks cooldowns and resources across ticks. Without it only the current resources gate casting.
+	Skills *SkillTracker
+
+	// Verifier checks the outcome of commands and makes us avoid what failed. Optional.
+	Verifier *Verifier
+
+	// Watchdog detects that we are stuck and takes over to recover. Optional.
+	Watchdog *Watchdog
+
+	// ShopCache skips searching the shop again while nothing changed. Optional.
+	ShopCache *ShopCache
+
+	// Memory completes the current level with what we have seen of it before
+	// and keeps the explored tiles. Without it only the tiles in sight count as explored.
+	Memory *Memory
+}
+
+func (d Default) Decide(state swagger.DungeonsandtrollsGameState) *swagger.DungeonsandtrollsCommandsBatch {
+	// What happened last tick is judged by what we see, remembered monsters may be long gone.
+	if d.Memory != nil {
+		d.Memory.Observe(state)
+	}
+	if d.Skills != nil {
+		d.Skills.Observe(state)
+	}
+	if d.Verifier != nil {
+		d.Verifier.Observe(state)
+	}
+	if d.Watchdog != nil {
+		d.Watchdog.Observe(state)
+	}
+	if d.Memory != nil {
+		state = d.Memory.Recall(state)
+	}
+
+	command, ok := d.recover(&state)
+	if !ok {
+		command = d.run(state)
+	}
+
+	if d.Skills != nil {
+		d.Skills.Issued(command)
+	}
+	if d.Verifier != nil {
+		d.Verifier.Issued(command)
+	}
+	if d.Watchdog != nil {
+		d.Watchdog.Issued(command)
+	}
+	return command
+}
+
+// ready reports whether the skill can be cast this tick.
+func (d Default) ready(state *swagger.DungeonsandtrollsGameState, skill *swagger.DungeonsandtrollsSkill) bool {
+	if skill.Cost != nil && !haveRequiredAttirbutes(state.Character.Attributes, skill.Cost) {
+		return false
+	}
+	if d.Verifier != nil && d.Verifier.blacklisted(skill, state.Tick) {
+		return false
+	}
+	return d.Skills == nil || d.Skills.CastableAt(state, skill) <= state.Tick
+}
+
+// schedule tells when skills can be cast in the ticks to come.
+// Resources are left to the planner, only the cooldowns and the verifier's blacklist limit later ticks.
+func (d Default) schedule(state *swagger.DungeonsandtrollsGameState) schedule {
+	return schedule{
+		ready: func(skill *swagger.DungeonsandtrollsSkill, tick int32) bool {
+			if tick == state.Tick {
+				return d.ready(state, skill)
+			}
+			if d.Verifier != nil && d.Verifier.blacklisted(skill, tick) {
+				return false
+			}
+			return d.Skills == nil || d.Skills.offCooldownAt(state, skill) <= tick
+		},
+		cooldown: func(skill *swagger.DungeonsandtrollsSkill) int32 {
+			if d.Skills == nil {
+				return 1
+			}
+			return d.Skills.cooldown(skill)
+		},
+	}
+}
+
+// moveCost is the cost of stepping on tiles when moving, ignoring monsters on the except object.
+func (d Default) moveCost(state *swagger.DungeonsandtrollsGameState, except *swagger.DungeonsandtrollsMapObjects) grid.Cost {
+	cost := newThreatMap(state, except).cost(d.RiskTolerance)
+	if d.Verifier != nil {
+		cost = grid.Sum(cost, d.Verifier.cost(state.Tick))
+	}
+	return cost
+}
+
+// First returns the command of the first strategy that decides to do something.
+func First(strategies ...Strategy) Strategy {
+	return StrategyFunc(func(state swagger.DungeonsandtrollsGameState) *swagger.DungeonsandtrollsCommandsBatch {
+		for _, strategy := range strategies {
+			if command := strategy.Decide(state); command != nil {
+				return command
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,86 @@
+package bot
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+)
+
+func TestFirst(t *testing.T) {
+	skip := StrategyFunc(func(swagger.DungeonsandtrollsGameState) *swagger.DungeonsandtrollsCommandsBatch {
+		return nil
+	})
+	yell := func(text string) Strategy {
+		return StrategyFunc(func(swagger.DungeonsandtrollsGameState) *swagger.DungeonsandtrollsCommandsBatch {
+			return &swagger.DungeonsandtrollsCommandsBatch{Yell: &swagger.DungeonsandtrollsMessage{Text: text}}
+		})
+	}
+
+	command := First(skip, yell("a"), yell("b")).Decide(swagger.DungeonsandtrollsGameState{})
+	if command == nil || command.Yell.Text != "a" {
+		t.Errorf("got %+v, want yell a", command)
+	}
+
+	if command := First(skip, skip).Decide(swagger.DungeonsandtrollsGameState{}); command != nil {
+		t.Errorf("got %+v, want nil", command)
+	}
+}
+
+func TestDecideVerifiesWhatIsInSight(t *testing.T) {
+	if !testing.Verbose() {
+		log.SetOutput(io.Discard)
+		defer log.SetOutput(os.Stderr)
+	}
+
+	load := func() swagger.DungeonsandtrollsGameState {
+		data, err := os.ReadFile(filepath.Join("testdata", "run", "attack", "state.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var state swagger.DungeonsandtrollsGameState
+		if err := json.Unmarshal(data, &state); err != nil {
+			t.Fatal(err)
+		}
+		return state
+	}
+
+	play := Default{Verifier: NewVerifier(), Memory: NewMemory()}
+	state := load()
+	command := play.Decide(state)
+	if command == nil || command.Skill == nil || command.Skill.TargetId == "" {
+		t.Fatalf("got %+v, want a skill on a monster", command)
+	}
+
+	// The target walked out of sight, the memory still has it with the same life.
+	next := load()
+	next.Tick++
+	next.Character.Attributes.Mana--
+	for i := range next.Map_.Levels {
+		level := &next.Map_.Levels[i]
+		if level.Level != next.CurrentLevel {
+			continue
+		}
+		for j := range level.Objects {
+			object := &level.Objects[j]
+			if len(object.Monsters) == 0 || object.Monsters[0].Id != command.Skill.TargetId {
+				continue
+			}
+			for k := range level.PlayerMap {
+				if *level.PlayerMap[k].Position == *object.Position {
+					level.PlayerMap[k].LineOfSight = false
+				}
+			}
+			object.Monsters = nil
+		}
+	}
+	play.Decide(next)
+
+	if got := play.Verifier.failures[command.Skill.SkillId]; got != 0 {
+		t.Errorf("skill failed %d times against a remembered monster, want 0", got)
+	}
+}
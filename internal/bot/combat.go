@@ -0,0 +1,44 @@
+package bot
+
+import (
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+)
+
+// resistance returns the resistance of attrs against the damage type.
+func resistance(attrs *swagger.DungeonsandtrollsAttributes, damageType swagger.DungeonsandtrollsDamageType) float32 {
+	switch damageType {
+	case swagger.SLASH_DungeonsandtrollsDamageType:
+		return attrs.SlashResist
+	case swagger.PIERCE_DungeonsandtrollsDamageType:
+		return attrs.PierceResist
+	case swagger.FIRE_DungeonsandtrollsDamageType:
+		return attrs.FireResist
+	case swagger.POISON_DungeonsandtrollsDamageType:
+		return attrs.PoisonResist
+	case swagger.ELECTRIC_DungeonsandtrollsDamageType:
+		return attrs.ElectricResist
+	}
+	return 0
+}
+
+// mitigate reduces damage by resistance.
+// The server does not document the formula, we assume ten points of resistance halve the damage.
+func mitigate(damage, resist float32) float32 {
+	if resist <= 0 {
+		return damage
+	}
+	return damage * 10 / (10 + resist)
+}
+
+// skillDamage is the expected damage of a single use of the skill against a target with the given attributes.
+// Without known target attributes resistances are ignored.
+func skillDamage(attrs *swagger.DungeonsandtrollsAttributes, skill *swagger.DungeonsandtrollsSkill, target *swagger.DungeonsandtrollsAttributes) float32 {
+	if skill.DamageAmount == nil {
+		return 0
+	}
+	damage := calculateAttributesValue(attrs, skill.DamageAmount)
+	if target == nil || skill.DamageType == nil {
+		return damage
+	}
+	return mitigate(damage, resistance(target, *skill.DamageType))
+}
@@ -0,0 +1,32 @@
+package bot
+
+import (
+	"testing"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+)
+
+func TestSkillDamage(t *testing.T) {
+	fire := swagger.FIRE_DungeonsandtrollsDamageType
+	skill := &swagger.DungeonsandtrollsSkill{
+		DamageAmount: &swagger.DungeonsandtrollsAttributes{Intelligence: 2, Constant: 4},
+		DamageType:   &fire,
+	}
+	attrs := &swagger.DungeonsandtrollsAttributes{Intelligence: 8}
+
+	tests := []struct {
+		name   string
+		target *swagger.DungeonsandtrollsAttributes
+		want   float32
+	}{
+		{"unknown target", nil, 20},
+		{"no resistance", &swagger.DungeonsandtrollsAttributes{SlashResist: 10}, 20},
+		{"resisted", &swagger.DungeonsandtrollsAttributes{FireResist: 10}, 10},
+		{"negative resistance", &swagger.DungeonsandtrollsAttributes{FireResist: -5}, 20},
+	}
+	for _, tt := range tests {
+		if got := skillDamage(attrs, skill, tt.target); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
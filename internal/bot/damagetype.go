@@ -0,0 +1,129 @@
+package bot
+
+import (
+	"log"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+	"golang.org/x/exp/slices"
+)
+
+var damageTypes = []swagger.DungeonsandtrollsDamageType{
+	swagger.SLASH_DungeonsandtrollsDamageType,
+	swagger.PIERCE_DungeonsandtrollsDamageType,
+	swagger.FIRE_DungeonsandtrollsDamageType,
+	swagger.POISON_DungeonsandtrollsDamageType,
+	swagger.ELECTRIC_DungeonsandtrollsDamageType,
+}
+
+// fallbackDamageType is used when nothing is known about the available skills.
+const fallbackDamageType = swagger.FIRE_DungeonsandtrollsDamageType
+
+// isAttackSkill reports whether the skill deals damage to a targeted character or to an area.
+func isAttackSkill(skill *swagger.DungeonsandtrollsSkill) bool {
+	if skill.DamageAmount == nil || skill.DamageType == nil || skill.Target == nil {
+		return false
+	}
+	if skill.CasterEffects != nil && skill.CasterEffects.Attributes != nil && skill.CasterEffects.Attributes.Mana != nil && skill.CasterEffects.Attributes.Mana.Mana < 0 {
+		return false
+	}
+	return *skill.Target == swagger.CHARACTER_SkillTarget || isAreaSkill(skill)
+}
+
+// chooseDamageTypes ranks damage types by the best damage we have equipped or can afford and use from the shop,
+// reduced by the given resistances of the monsters, and returns the best count of them.
+func chooseDamageTypes(state *swagger.DungeonsandtrollsGameState, count int, resists map[swagger.DungeonsandtrollsDamageType]float32) []swagger.DungeonsandtrollsDamageType {
+	count = max(count, 1)
+
+	damage := map[swagger.DungeonsandtrollsDamageType]float32{}
+	addSkills := func(skills []swagger.DungeonsandtrollsSkill) {
+		for i := range skills {
+			skill := &skills[i]
+			if !isAttackSkill(skill) {
+				continue
+			}
+			damage[*skill.DamageType] = max(damage[*skill.DamageType], skillDamage(state.Character.Attributes, skill, nil))
+		}
+	}
+	for _, item := range state.Character.Equip {
+		addSkills(item.Skills)
+	}
+	for _, item := range state.ShopItems {
+		if item.Price <= state.Character.Money && haveRequiredAttirbutes(state.Character.Attributes, item.Requirements) {
+			addSkills(item.Skills)
+		}
+	}
+
+	type scored struct {
+		damageType swagger.DungeonsandtrollsDamageType
+		score      float32
+	}
+	var ranking []scored
+	for _, damageType := range damageTypes {
+		score := mitigate(damage[damageType], resists[damageType])
+		if score > 0 {
+			ranking = append(ranking, scored{damageType, score})
+		}
+	}
+	slices.SortStableFunc(ranking, func(a, b scored) int {
+		if a.score > b.score {
+			return -1
+		}
+		if a.score < b.score {
+			return 1
+		}
+		return 0
+	})
+
+	if len(ranking) == 0 {
+		return []swagger.DungeonsandtrollsDamageType{fallbackDamageType}
+	}
+
+	res := make([]swagger.DungeonsandtrollsDamageType, 0, count)
+	for _, s := range ranking[:min(count, len(ranking))] {
+		res = append(res, s.damageType)
+	}
+	log.Println("Damage types:", res)
+	return res
+}
+
+// damageTypes chooses the damage types to build the loadout around.
+// Monsters on the current level tell what they resist. Without any, like in the shop,
+// the resistances remembered from the deepest level we have been to are used.
+func (d Default) damageTypes(state *swagger.DungeonsandtrollsGameState) []swagger.DungeonsandtrollsDamageType {
+	resists, ok := levelResistances(state)
+	if !ok && d.Memory != nil {
+		resists = d.Memory.resistances()
+	}
+	return chooseDamageTypes(state, d.DamageTypes, resists)
+}
+
+// levelResistances returns the average resistance of the hostile monsters on the current level to each damage type.
+// It returns false if there are none.
+func levelResistances(state *swagger.DungeonsandtrollsGameState) (map[swagger.DungeonsandtrollsDamageType]float32, bool) {
+	total := map[swagger.DungeonsandtrollsDamageType]float32{}
+	count := 0
+
+	for _, level := range state.Map_.Levels {
+		if level.Level != state.CurrentLevel {
+			continue
+		}
+		for _, object := range level.Objects {
+			for _, monster := range object.Monsters {
+				if monster.Faction == "neutral" || monster.Attributes == nil {
+					continue
+				}
+				count++
+				for _, damageType := range damageTypes {
+					total[damageType] += resistance(monster.Attributes, damageType)
+				}
+			}
+		}
+	}
+
+	if count > 0 {
+		for damageType := range total {
+			total[damageType] /= float32(count)
+		}
+	}
+	return total, count > 0
+}
@@ -0,0 +1,66 @@
+package bot
+
+import (
+	"testing"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+)
+
+func TestDamageTypesRemembered(t *testing.T) {
+	skill := func(id string, damageType swagger.DungeonsandtrollsDamageType) swagger.DungeonsandtrollsSkill {
+		target := swagger.CHARACTER_SkillTarget
+		return swagger.DungeonsandtrollsSkill{
+			Id:           id,
+			Target:       &target,
+			DamageType:   &damageType,
+			DamageAmount: &swagger.DungeonsandtrollsAttributes{Constant: 10},
+		}
+	}
+	state := func(level int32, monsters ...swagger.DungeonsandtrollsMonster) swagger.DungeonsandtrollsGameState {
+		return swagger.DungeonsandtrollsGameState{
+			CurrentLevel:    level,
+			CurrentPosition: &swagger.DungeonsandtrollsPosition{},
+			Character: &swagger.DungeonsandtrollsCharacter{
+				Attributes: &swagger.DungeonsandtrollsAttributes{},
+				Equip: []swagger.DungeonsandtrollsItem{{Skills: []swagger.DungeonsandtrollsSkill{
+					skill("slash", swagger.SLASH_DungeonsandtrollsDamageType),
+					skill("fire", swagger.FIRE_DungeonsandtrollsDamageType),
+				}}},
+			},
+			Map_: &swagger.DungeonsandtrollsMap{Levels: []swagger.DungeonsandtrollsLevel{{
+				Level:   level,
+				Objects: []swagger.DungeonsandtrollsMapObjects{{Position: &swagger.DungeonsandtrollsPosition{PositionX: 1}, Monsters: monsters}},
+			}}},
+		}
+	}
+	armored := swagger.DungeonsandtrollsMonster{Id: "knight", Attributes: &swagger.DungeonsandtrollsAttributes{SlashResist: 10}}
+	burning := swagger.DungeonsandtrollsMonster{Id: "imp", Attributes: &swagger.DungeonsandtrollsAttributes{FireResist: 10}}
+
+	tests := []struct {
+		name   string
+		seen   []swagger.DungeonsandtrollsGameState
+		memory bool
+		want   swagger.DungeonsandtrollsDamageType
+	}{
+		{"nothing known", nil, true, swagger.SLASH_DungeonsandtrollsDamageType},
+		{"without memory", []swagger.DungeonsandtrollsGameState{state(1, armored)}, false, swagger.SLASH_DungeonsandtrollsDamageType},
+		{"remembered level", []swagger.DungeonsandtrollsGameState{state(1, armored)}, true, swagger.FIRE_DungeonsandtrollsDamageType},
+		{"deepest level", []swagger.DungeonsandtrollsGameState{state(2, armored), state(1, burning)}, true, swagger.FIRE_DungeonsandtrollsDamageType},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			d := Default{}
+			if tc.memory {
+				d.Memory = NewMemory()
+				for _, s := range tc.seen {
+					d.Memory.Observe(s)
+				}
+			}
+			// In the shop there are no monsters.
+			shop := state(0)
+			if got := d.damageTypes(&shop); len(got) != 1 || got[0] != tc.want {
+				t.Errorf("damage types %v, want [%v]", got, tc.want)
+			}
+		})
+	}
+}
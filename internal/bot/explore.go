@@ -0,0 +1,70 @@
+package bot
+
+import (
+	"log"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+	"github.com/liennie/gdt/internal/grid"
+)
+
+// exploreGainWeight is how many steps an unseen tile next to the frontier is worth.
+const exploreGainWeight = 0.5
+
+// findFrontier returns the reachable unexplored tile next to an explored one that is closest
+// and borders the most unexplored tiles. It returns nil when the whole reachable level is explored.
+func findFrontier(state *swagger.DungeonsandtrollsGameState, explored func(pos swagger.DungeonsandtrollsPosition) bool) *swagger.DungeonsandtrollsPosition {
+	for _, level := range state.Map_.Levels {
+		if level.Level != state.CurrentLevel {
+			continue
+		}
+
+		reachable := map[swagger.DungeonsandtrollsPosition]bool{}
+		for _, pm := range level.PlayerMap {
+			if pm.Position != nil && pm.Distance >= 0 {
+				reachable[*pm.Position] = true
+			}
+		}
+
+		var best *swagger.DungeonsandtrollsPosition
+		bestScore := float32(0)
+		for _, pm := range level.PlayerMap {
+			if pm.Position == nil || pm.Distance <= 0 || explored(*pm.Position) {
+				continue
+			}
+
+			frontier := false
+			gain := 0
+			for _, pos := range grid.Neighbors(*pm.Position) {
+				if explored(pos) {
+					frontier = true
+				} else if reachable[pos] {
+					gain++
+				}
+			}
+			if !frontier {
+				continue
+			}
+
+			score := float32(gain)*exploreGainWeight - float32(pm.Distance)
+			if best == nil || score > bestScore {
+				best, bestScore = pm.Position, score
+			}
+		}
+		if best != nil {
+			log.Printf("Exploring towards %+v\n", *best)
+		}
+		return best
+	}
+	return nil
+}
+
+// explore returns where to go to discover more of the current level.
+// Without a Memory only the tiles in sight this tick count as explored.
+func (d Default) explore(state *swagger.DungeonsandtrollsGameState) *swagger.DungeonsandtrollsPosition {
+	memory := d.Memory
+	if memory == nil {
+		memory = NewMemory()
+		memory.Observe(*state)
+	}
+	return findFrontier(state, memory.explored(state.CurrentLevel))
+}
@@ -0,0 +1,92 @@
+package bot
+
+import (
+	"path/filepath"
+	"testing"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+)
+
+func TestExplore(t *testing.T) {
+	// A corridor of tiles 0 to width-1, we see two tiles ahead. Tiles from the wall on are unreachable.
+	state := func(x, wall, width int32) swagger.DungeonsandtrollsGameState {
+		var playerMap []swagger.DungeonsandtrollsPlayerSpecificMap
+		for i := int32(0); i < width; i++ {
+			distance := int32(abs(int(i - x)))
+			if i >= wall {
+				distance = -1
+			}
+			playerMap = append(playerMap, swagger.DungeonsandtrollsPlayerSpecificMap{
+				Position:    &swagger.DungeonsandtrollsPosition{PositionX: i},
+				Distance:    distance,
+				LineOfSight: abs(int(i-x)) <= 2,
+			})
+		}
+		return swagger.DungeonsandtrollsGameState{
+			CurrentPosition: &swagger.DungeonsandtrollsPosition{PositionX: x},
+			Map_: &swagger.DungeonsandtrollsMap{Levels: []swagger.DungeonsandtrollsLevel{
+				{Width: width, Height: 1, PlayerMap: playerMap},
+			}},
+		}
+	}
+
+	tests := []struct {
+		name string
+		// path are the tiles we have been on, ending with the current one.
+		path   []int32
+		wall   int32
+		memory bool
+		// save saves the memory and loads it back before the last tile of the path.
+		save bool
+		// width is the width of the level on the last tile of the path, 5 if zero.
+		width int32
+		// want is the tile to explore, -1 for none.
+		want int32
+	}{
+		{name: "at the start", path: []int32{0}, wall: 5, memory: true, want: 3},
+		{name: "walked back", path: []int32{0, 2, 0}, wall: 5, memory: true, want: -1},
+		{name: "walked back without a memory", path: []int32{0, 2, 0}, wall: 5, want: 3},
+		{name: "the rest is unreachable", path: []int32{0}, wall: 3, memory: true, want: -1},
+		{name: "saved and loaded", path: []int32{0, 2, 0}, wall: 5, memory: true, save: true, want: -1},
+		{name: "another dungeon", path: []int32{0, 2, 0}, wall: 6, memory: true, width: 6, want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var play Default
+			if tt.memory {
+				play.Memory = NewMemory()
+			}
+			var s swagger.DungeonsandtrollsGameState
+			for i, x := range tt.path {
+				width := int32(5)
+				if i == len(tt.path)-1 {
+					if tt.width != 0 {
+						width = tt.width
+					}
+					if tt.save {
+						path := filepath.Join(t.TempDir(), "memory.json")
+						if err := play.Memory.Save(path); err != nil {
+							t.Fatal(err)
+						}
+						var err error
+						if play.Memory, err = LoadMemory(path); err != nil {
+							t.Fatal(err)
+						}
+					}
+				}
+				s = state(x, tt.wall, width)
+				if play.Memory != nil {
+					play.Memory.Observe(s)
+				}
+			}
+
+			got := play.explore(&s)
+			switch {
+			case tt.want < 0 && got != nil:
+				t.Errorf("exploring towards %+v, want nothing", *got)
+			case tt.want >= 0 && (got == nil || got.PositionX != tt.want):
+				t.Errorf("exploring towards %+v, want tile %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package bot
+
+import (
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+)
+
+// testState returns a state of our character "me" standing on tile x of a level
+// of width tiles in a single row, without any objects.
+func testState(tick, x, width int32) swagger.DungeonsandtrollsGameState {
+	return swagger.DungeonsandtrollsGameState{
+		Tick:            tick,
+		CurrentPosition: &swagger.DungeonsandtrollsPosition{PositionX: x},
+		Character: &swagger.DungeonsandtrollsCharacter{
+			Id:            "me",
+			Attributes:    &swagger.DungeonsandtrollsAttributes{},
+			MaxAttributes: &swagger.DungeonsandtrollsAttributes{},
+		},
+		Map_: &swagger.DungeonsandtrollsMap{Levels: []swagger.DungeonsandtrollsLevel{{
+			Width:  width,
+			Height: 1,
+		}}},
+	}
+}
+
+// testEquip gives our character an item with the skills.
+func testEquip(state *swagger.DungeonsandtrollsGameState, skills ...swagger.DungeonsandtrollsSkill) {
+	state.Character.Equip = append(state.Character.Equip, swagger.DungeonsandtrollsItem{Skills: skills})
+}
+
+// testReject adds an error event rejecting the command our character sent in the previous tick.
+func testReject(state *swagger.DungeonsandtrollsGameState, message string) {
+	errorType := swagger.ERROR__DungeonsandtrollsEventType
+	state.Events = append(state.Events, swagger.DungeonsandtrollsEvent{Type_: &errorType, PlayerId: state.Character.Id, Message: message})
+}
+
+// observer is a helper of Default judging commands by the state of the next tick.
+type observer interface {
+	Observe(state swagger.DungeonsandtrollsGameState)
+	Issued(command *swagger.DungeonsandtrollsCommandsBatch)
+}
+
+// testStep is a tick observed by an observer and the command issued in it.
+type testStep struct {
+	state   swagger.DungeonsandtrollsGameState
+	command *swagger.DungeonsandtrollsCommandsBatch
+}
+
+// testObserve feeds the steps to the observer in order.
+func testObserve(o observer, steps []testStep) {
+	for _, step := range steps {
+		o.Observe(step.state)
+		o.Issued(step.command)
+	}
+}
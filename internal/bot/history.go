@@ -0,0 +1,43 @@
+package bot
+
+import (
+	"log"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+)
+
+// history is the previously observed state and the command issued in its tick,
+// kept by the helpers of Default that judge a command by the state of the next tick.
+type history struct {
+	previous *swagger.DungeonsandtrollsGameState
+	command  *swagger.DungeonsandtrollsCommandsBatch
+}
+
+// advance reports whether the state is of a new tick. A tick that went back is a restarted game,
+// the previous state and command are forgotten and restarted is true.
+func (h *history) advance(state *swagger.DungeonsandtrollsGameState) (next, restarted bool) {
+	if h.previous == nil {
+		return true, false
+	}
+	if state.Tick == h.previous.Tick {
+		return false, false
+	}
+	if state.Tick < h.previous.Tick {
+		log.Printf("Tick went back from %d to %d, the game restarted\n", h.previous.Tick, state.Tick)
+		h.previous = nil
+		h.command = nil
+		return true, true
+	}
+	return true, false
+}
+
+// record makes the state the previous one for the next tick.
+func (h *history) record(state *swagger.DungeonsandtrollsGameState) {
+	h.previous = state
+	h.command = nil
+}
+
+// Issued records the command sent in the tick of the last observed state.
+func (h *history) Issued(command *swagger.DungeonsandtrollsCommandsBatch) {
+	h.command = command
+}
@@ -0,0 +1,180 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+	"golang.org/x/exp/slices"
+)
+
+// itemSlots lists every equipment slot, the main hand first as it carries the most important skills.
+var itemSlots = []swagger.DungeonsandtrollsItemType{
+	swagger.MAIN_HAND_DungeonsandtrollsItemType,
+	swagger.OFF_HAND_DungeonsandtrollsItemType,
+	swagger.HEAD_DungeonsandtrollsItemType,
+	swagger.BODY_DungeonsandtrollsItemType,
+	swagger.LEGS_DungeonsandtrollsItemType,
+	swagger.NECK_DungeonsandtrollsItemType,
+}
+
+// loadout is a set of items with at most one item per slot.
+// The API has no inventory nor equip command, bought and picked up items are equipped right away,
+// replacing the item in the slot, so the character's equip is everything it owns.
+type loadout []swagger.DungeonsandtrollsItem
+
+func (l loadout) get(slot swagger.DungeonsandtrollsItemType) (swagger.DungeonsandtrollsItem, bool) {
+	for _, item := range l {
+		if item.Slot != nil && *item.Slot == slot {
+			return item, true
+		}
+	}
+	return swagger.DungeonsandtrollsItem{}, false
+}
+
+func (l loadout) cost() int {
+	total := 0
+	for _, item := range l {
+		total += int(item.Price)
+	}
+	return total
+}
+
+// replace returns a copy of the loadout with the item in place of the item in its slot.
+func (l loadout) replace(item swagger.DungeonsandtrollsItem) loadout {
+	items := loadout{}
+	for _, owned := range l {
+		if owned.Slot == nil || *owned.Slot != *item.Slot {
+			items = append(items, owned)
+		}
+	}
+	return append(items, item)
+}
+
+// baseAttributes returns the attributes of the character without the bonuses of the equipped items.
+func baseAttributes(state *swagger.DungeonsandtrollsGameState) *swagger.DungeonsandtrollsAttributes {
+	bonuses := loadout(state.Character.Equip).attributes(&swagger.DungeonsandtrollsAttributes{})
+	return subtractAttributes(state.Character.Attributes, bonuses)
+}
+
+// attributes returns base with the attribute bonuses of all the items added.
+func (l loadout) attributes(base *swagger.DungeonsandtrollsAttributes) *swagger.DungeonsandtrollsAttributes {
+	attrs := []*swagger.DungeonsandtrollsAttributes{base}
+	for _, item := range l {
+		if item.Attributes != nil {
+			attrs = append(attrs, item.Attributes)
+		}
+	}
+	return addAttributes(attrs...)
+}
+
+var (
+	errSlotTaken         = errors.New("slot already taken")
+	errOverBudget        = errors.New("over budget")
+	errRequirementsUnmet = errors.New("requirements not met")
+)
+
+// validate checks that the loadout has at most one item per slot, that it fits within budget
+// and that requirements of every item are met by base with the bonuses of all the items.
+func (l loadout) validate(base *swagger.DungeonsandtrollsAttributes, budget int) error {
+	slots := map[swagger.DungeonsandtrollsItemType]string{}
+	for _, item := range l {
+		if item.Slot == nil {
+			continue
+		}
+		if other, ok := slots[*item.Slot]; ok {
+			return fmt.Errorf("%w: %q and %q are both %s items", errSlotTaken, other, item.Name, *item.Slot)
+		}
+		slots[*item.Slot] = item.Name
+	}
+
+	if cost := l.cost(); cost > budget {
+		return fmt.Errorf("%w: costs %d, have %d", errOverBudget, cost, budget)
+	}
+
+	attrs := l.attributes(base)
+	for _, item := range l {
+		if item.Requirements == nil {
+			continue
+		}
+		if missing := missingAttributes(attrs, item.Requirements); len(missing) > 0 {
+			return fmt.Errorf("%w: %q needs %s", errRequirementsUnmet, item.Name, strings.Join(missing, ", "))
+		}
+	}
+	return nil
+}
+
+// loadoutScore rates a loadout given the attributes the character has with it equipped.
+type loadoutScore func(items loadout, attrs *swagger.DungeonsandtrollsAttributes) float32
+
+// loadoutOptimizer finds the best scoring loadout within budget with a beam search over the slots.
+// Owned items cost nothing and their slots can't be left empty as buying only replaces items.
+type loadoutOptimizer struct {
+	items     []swagger.DungeonsandtrollsItem
+	owned     loadout
+	slots     []swagger.DungeonsandtrollsItemType
+	budget    int
+	beamWidth int
+	score     loadoutScore
+}
+
+func (o loadoutOptimizer) best(base *swagger.DungeonsandtrollsAttributes) loadout {
+	type candidate struct {
+		items loadout
+		value float32
+		valid bool
+	}
+
+	beam := []candidate{{valid: true}}
+	for _, slot := range o.slots {
+		options := []swagger.DungeonsandtrollsItem{}
+		var next []candidate
+		if item, ok := o.owned.get(slot); ok {
+			item.Price = 0
+			options = append(options, item)
+		} else {
+			next = append(next, beam...)
+		}
+		for _, item := range o.items {
+			if item.Slot != nil && *item.Slot == slot {
+				options = append(options, item)
+			}
+		}
+
+		for _, c := range beam {
+			for _, item := range options {
+
+				items := append(c.items[:len(c.items):len(c.items)], item)
+				// Items in later slots may still fulfill the requirements.
+				err := items.validate(base, o.budget)
+				if err != nil && !errors.Is(err, errRequirementsUnmet) {
+					continue
+				}
+				next = append(next, candidate{
+					items: items,
+					value: o.score(items, items.attributes(base)),
+					valid: err == nil,
+				})
+			}
+		}
+
+		slices.SortStableFunc(next, func(a, b candidate) int {
+			if a.value > b.value {
+				return -1
+			}
+			if a.value < b.value {
+				return 1
+			}
+			return 0
+		})
+		beam = next[:min(len(next), o.beamWidth)]
+	}
+
+	for _, c := range beam {
+		if c.valid {
+			return c.items
+		}
+	}
+	return nil
+}
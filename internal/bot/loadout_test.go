@@ -0,0 +1,89 @@
+package bot
+
+import (
+	"errors"
+	"testing"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+	"golang.org/x/exp/slices"
+)
+
+func testItem(id string, slot swagger.DungeonsandtrollsItemType, price int32, attrs, requirements swagger.DungeonsandtrollsAttributes) swagger.DungeonsandtrollsItem {
+	return swagger.DungeonsandtrollsItem{
+		Id:           id,
+		Name:         id,
+		Slot:         &slot,
+		Price:        price,
+		Attributes:   &attrs,
+		Requirements: &requirements,
+	}
+}
+
+func TestLoadoutValidate(t *testing.T) {
+	sword := testItem("sword", swagger.MAIN_HAND_DungeonsandtrollsItemType, 100, swagger.DungeonsandtrollsAttributes{}, swagger.DungeonsandtrollsAttributes{Strength: 6})
+	staff := testItem("staff", swagger.MAIN_HAND_DungeonsandtrollsItemType, 50, swagger.DungeonsandtrollsAttributes{}, swagger.DungeonsandtrollsAttributes{})
+	amulet := testItem("amulet", swagger.NECK_DungeonsandtrollsItemType, 50, swagger.DungeonsandtrollsAttributes{Strength: 2}, swagger.DungeonsandtrollsAttributes{})
+	base := &swagger.DungeonsandtrollsAttributes{Strength: 5}
+
+	tests := []struct {
+		name    string
+		items   loadout
+		budget  int
+		wantErr error
+	}{
+		{"empty", nil, 0, nil},
+		{"requirements met by base", loadout{staff}, 100, nil},
+		{"requirements unmet", loadout{sword}, 200, errRequirementsUnmet},
+		{"requirements met by items", loadout{sword, amulet}, 200, nil},
+		{"over budget", loadout{sword, amulet}, 149, errOverBudget},
+		{"slot taken", loadout{sword, staff}, 200, errSlotTaken},
+	}
+	for _, tt := range tests {
+		err := tt.items.validate(base, tt.budget)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestLoadoutOptimizer(t *testing.T) {
+	sword := testItem("sword", swagger.MAIN_HAND_DungeonsandtrollsItemType, 100, swagger.DungeonsandtrollsAttributes{}, swagger.DungeonsandtrollsAttributes{Strength: 6})
+	stick := testItem("stick", swagger.MAIN_HAND_DungeonsandtrollsItemType, 10, swagger.DungeonsandtrollsAttributes{}, swagger.DungeonsandtrollsAttributes{})
+	amulet := testItem("amulet", swagger.NECK_DungeonsandtrollsItemType, 50, swagger.DungeonsandtrollsAttributes{Strength: 2}, swagger.DungeonsandtrollsAttributes{})
+	ring := testItem("ring", swagger.NECK_DungeonsandtrollsItemType, 10, swagger.DungeonsandtrollsAttributes{}, swagger.DungeonsandtrollsAttributes{})
+
+	values := map[string]float32{"sword": 10, "stick": 1, "amulet": 1, "ring": 2}
+	optimizer := loadoutOptimizer{
+		items:     []swagger.DungeonsandtrollsItem{sword, stick, amulet, ring},
+		slots:     itemSlots,
+		beamWidth: 10,
+		score: func(items loadout, attrs *swagger.DungeonsandtrollsAttributes) float32 {
+			value := float32(0)
+			for _, item := range items {
+				value += values[item.Id]
+			}
+			return value
+		},
+	}
+	base := &swagger.DungeonsandtrollsAttributes{Strength: 5}
+
+	tests := []struct {
+		budget int
+		want   []string
+	}{
+		{0, nil},
+		{20, []string{"stick", "ring"}},
+		{149, []string{"stick", "ring"}},
+		{150, []string{"sword", "amulet"}},
+	}
+	for _, tt := range tests {
+		optimizer.budget = tt.budget
+		var got []string
+		for _, item := range optimizer.best(base) {
+			got = append(got, item.Id)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("budget %d: got %v, want %v", tt.budget, got, tt.want)
+		}
+	}
+}
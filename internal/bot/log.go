@@ -0,0 +1,37 @@
+package bot
+
+import (
+	"log"
+	"reflect"
+	"strconv"
+)
+
+func LogStruct(v reflect.Value, name string) {
+	if v.Type().Kind() == reflect.Pointer {
+		if !v.IsNil() {
+			LogStruct(v.Elem(), name)
+		}
+		return
+	}
+
+	if v.Type().Kind() == reflect.Struct {
+		for n := 0; n < v.Type().NumField(); n++ {
+			field := v.Field(n)
+			fieldName := name + "." + v.Type().Field(n).Name
+			LogStruct(field, fieldName)
+		}
+		return
+	}
+
+	if v.Type().Kind() == reflect.Slice {
+		for n := 0; n < v.Len(); n++ {
+			field := v.Index(n)
+			fieldName := name + "[" + strconv.Itoa(n) + "]"
+			LogStruct(field, fieldName)
+		}
+		return
+	}
+
+	log.Printf("%s: %v", name, v.Interface())
+	return
+}
@@ -0,0 +1,71 @@
+package bot
+
+import (
+	"log"
+	"math"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+)
+
+// loot is an item lying on the current level that improves our loadout.
+type loot struct {
+	item     swagger.DungeonsandtrollsItem
+	position swagger.DungeonsandtrollsPosition
+	gain     float32
+}
+
+// findLoot returns the item on the current level with the best loadout improvement per step,
+// skipping items on tiles threatened by monsters or effects.
+func findLoot(state *swagger.DungeonsandtrollsGameState, types []swagger.DungeonsandtrollsDamageType) *loot {
+	owned := loadout(state.Character.Equip)
+	base := baseAttributes(state)
+	score := shopScore(types)
+	current := score(owned, owned.attributes(base))
+	threats := newThreatMap(state, nil)
+
+	var best *loot
+	bestValue := float32(0)
+	for _, level := range state.Map_.Levels {
+		if level.Level != state.CurrentLevel {
+			continue
+		}
+
+		for _, object := range level.Objects {
+			if len(object.Items) == 0 || threats.Damage(*object.Position) > 0 {
+				continue
+			}
+			dist := mapDistance(*object.Position, *state)
+			if *object.Position == *state.CurrentPosition {
+				dist = 0
+			} else if dist == math.MaxInt {
+				continue
+			}
+
+			for _, item := range object.Items {
+				if item.Slot == nil {
+					continue
+				}
+				// Picking up is free, only the requirements matter.
+				items := owned.replace(item)
+				if err := items.validate(base, math.MaxInt); err != nil {
+					continue
+				}
+
+				gain := score(items, items.attributes(base)) - current
+				if value := gain / float32(1+dist); gain > 0 && value > bestValue {
+					bestValue = value
+					best = &loot{
+						item:     item,
+						position: *object.Position,
+						gain:     gain,
+					}
+				}
+			}
+		}
+	}
+
+	if best != nil {
+		log.Printf("Found %s on position %+v improving the loadout by %.2f\n", best.item.Name, best.position, best.gain)
+	}
+	return best
+}
@@ -0,0 +1,115 @@
+package bot
+
+import (
+	"log"
+	"math"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+)
+
+func findStairs(state *swagger.DungeonsandtrollsGameState) *swagger.DungeonsandtrollsPosition {
+	level := state.CurrentLevel
+	for _, map_ := range state.Map_.Levels {
+		if map_.Level != level {
+			continue
+		}
+		maxPortal := 0
+		var portalPos swagger.DungeonsandtrollsPosition
+		for i := range map_.Objects {
+			object := map_.Objects[i]
+			if object.Portal != nil && object.Portal.DestinationFloor > int32(maxPortal) {
+				maxPortal = int(object.Portal.DestinationFloor)
+				portalPos = *object.Position
+			} else if object.IsStairs && state.CurrentLevel+1 > int32(maxPortal) {
+				maxPortal = int(state.CurrentLevel) + 1
+				portalPos = *object.Position
+			}
+		}
+		if maxPortal > 0 {
+			log.Printf("Found portal on position: %+v\n", portalPos)
+			return &portalPos
+		}
+	}
+	return nil
+}
+
+func findSpawn(state *swagger.DungeonsandtrollsGameState) *swagger.DungeonsandtrollsPosition {
+	for _, map_ := range state.Map_.Levels {
+		if map_.Level != state.CurrentLevel {
+			continue
+		}
+
+		for i := range map_.Objects {
+			object := map_.Objects[i]
+			if object.IsSpawn {
+				log.Printf("Found spawn on position: %+v\n", object.Position)
+				return object.Position
+			}
+		}
+	}
+	return nil
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
+func distance(a, b swagger.DungeonsandtrollsPosition) int {
+	return abs(int(a.PositionX)-int(b.PositionX)) + abs(int(a.PositionY)-int(b.PositionY))
+}
+
+func lineOfSight(position swagger.DungeonsandtrollsPosition, state swagger.DungeonsandtrollsGameState) bool {
+	for _, level := range state.Map_.Levels {
+		if level.Level != state.CurrentLevel {
+			continue
+		}
+
+		for _, pm := range level.PlayerMap {
+			if *pm.Position == position {
+				return pm.LineOfSight
+			}
+		}
+	}
+	return false
+}
+
+func mapDistance(position swagger.DungeonsandtrollsPosition, state swagger.DungeonsandtrollsGameState) int {
+	for _, level := range state.Map_.Levels {
+		if level.Level != state.CurrentLevel {
+			continue
+		}
+
+		for _, pm := range level.PlayerMap {
+			if *pm.Position == position {
+				return int(pm.Distance)
+			}
+		}
+	}
+	return math.MaxInt
+}
+
+func coords2pos(coords swagger.DungeonsandtrollsCoordinates) swagger.DungeonsandtrollsPosition {
+	return swagger.DungeonsandtrollsPosition{
+		PositionX: coords.PositionX,
+		PositionY: coords.PositionY,
+	}
+}
+
+func playersOnCurrentLevel(state swagger.DungeonsandtrollsGameState) []swagger.DungeonsandtrollsCharacter {
+	res := []swagger.DungeonsandtrollsCharacter{}
+
+	for _, level := range state.Map_.Levels {
+		if level.Level != state.CurrentLevel {
+			continue
+		}
+
+		for _, object := range level.Objects {
+			res = append(res, object.Players...)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,254 @@
+package bot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// monsterMemoryTicks is how long a monster we lost sight of is assumed to stay where we saw it.
+const monsterMemoryTicks = 10
+
+// Memory accumulates what we have seen of each level across ticks: the explored tiles, walls, doors,
+// stairs, portals with their destinations, the spawn and the monsters last seen out of sight.
+// It can be saved and loaded to keep it across runs while the dungeon stays the same.
+type Memory struct {
+	Levels map[int32]*levelMemory `json:"levels"`
+}
+
+type levelMemory struct {
+	Width  int32 `json:"width"`
+	Height int32 `json:"height"`
+	// Tiles are the static objects by tile index.
+	Tiles map[int32]swagger.DungeonsandtrollsMapObjects `json:"tiles"`
+	// Explored are the indices of the tiles we have stood on or had in sight.
+	Explored map[int32]bool `json:"explored,omitempty"`
+	// Monsters are the last sightings by monster ID. They are only good for a few ticks, so they are not saved.
+	Monsters map[string]sighting `json:"-"`
+	// Resistances are the average resistances of the hostile monsters last seen on the level.
+	Resistances map[swagger.DungeonsandtrollsDamageType]float32 `json:"resistances,omitempty"`
+}
+
+type sighting struct {
+	Monster  swagger.DungeonsandtrollsMonster  `json:"monster"`
+	Position swagger.DungeonsandtrollsPosition `json:"position"`
+	Tick     int32                             `json:"tick"`
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		Levels: map[int32]*levelMemory{},
+	}
+}
+
+// LoadMemory reads a memory saved by Save. A missing file gives an empty memory.
+func LoadMemory(path string) (*Memory, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return NewMemory(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read memory: %w", err)
+	}
+
+	m := NewMemory()
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("parse memory %s: %w", path, err)
+	}
+	if m.Levels == nil {
+		m.Levels = map[int32]*levelMemory{}
+	}
+	return m, nil
+}
+
+func (m *Memory) Save(path string) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("encode memory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write memory: %w", err)
+	}
+	return nil
+}
+
+func (l *levelMemory) index(pos swagger.DungeonsandtrollsPosition) int32 {
+	return pos.PositionY*l.Width + pos.PositionX
+}
+
+func (l *levelMemory) contains(pos swagger.DungeonsandtrollsPosition) bool {
+	return pos.PositionX >= 0 && pos.PositionX < l.Width && pos.PositionY >= 0 && pos.PositionY < l.Height
+}
+
+// staticObject returns the part of the object that doesn't move, or false if there is none.
+func staticObject(object swagger.DungeonsandtrollsMapObjects) (swagger.DungeonsandtrollsMapObjects, bool) {
+	static := swagger.DungeonsandtrollsMapObjects{
+		Position: object.Position,
+		IsWall:   object.IsWall,
+		IsDoor:   object.IsDoor,
+		IsSpawn:  object.IsSpawn,
+		IsStairs: object.IsStairs,
+		Portal:   object.Portal,
+	}
+	return static, static.IsWall || static.IsDoor || static.IsSpawn || static.IsStairs || static.Portal != nil
+}
+
+// Observe adds the current level of a new tick to the memory.
+// A level of a different size than remembered is a new dungeon and is forgotten.
+func (m *Memory) Observe(state swagger.DungeonsandtrollsGameState) {
+	if state.Map_ == nil {
+		return
+	}
+	for _, level := range state.Map_.Levels {
+		if level.Level != state.CurrentLevel {
+			continue
+		}
+
+		mem := m.Levels[level.Level]
+		if mem == nil || mem.Width != level.Width || mem.Height != level.Height {
+			if mem != nil {
+				log.Printf("Level %d changed, forgetting it\n", level.Level)
+			}
+			mem = &levelMemory{
+				Width:    level.Width,
+				Height:   level.Height,
+				Tiles:    map[int32]swagger.DungeonsandtrollsMapObjects{},
+				Monsters: map[string]sighting{},
+			}
+			m.Levels[level.Level] = mem
+		}
+		if mem.Monsters == nil {
+			mem.Monsters = map[string]sighting{}
+		}
+		if mem.Explored == nil {
+			mem.Explored = map[int32]bool{}
+		}
+
+		if resists, ok := levelResistances(&state); ok {
+			mem.Resistances = resists
+		}
+
+		// What we see replaces what we remember.
+		inSight := map[swagger.DungeonsandtrollsPosition]bool{}
+		for _, pm := range level.PlayerMap {
+			if pm.Position != nil && pm.LineOfSight {
+				inSight[*pm.Position] = true
+				delete(mem.Tiles, mem.index(*pm.Position))
+				mem.Explored[mem.index(*pm.Position)] = true
+			}
+		}
+		if state.CurrentPosition != nil && mem.contains(*state.CurrentPosition) {
+			mem.Explored[mem.index(*state.CurrentPosition)] = true
+		}
+		for id, seen := range mem.Monsters {
+			// Sightings from later ticks are from a game that restarted.
+			if inSight[seen.Position] || state.Tick-seen.Tick > monsterMemoryTicks || seen.Tick > state.Tick {
+				delete(mem.Monsters, id)
+			}
+		}
+
+		for _, object := range level.Objects {
+			if object.Position == nil {
+				continue
+			}
+			if static, ok := staticObject(object); ok {
+				mem.Tiles[mem.index(*object.Position)] = static
+			}
+			for _, monster := range object.Monsters {
+				mem.Monsters[monster.Id] = sighting{
+					Monster:  monster,
+					Position: *object.Position,
+					Tick:     state.Tick,
+				}
+			}
+		}
+	}
+}
+
+// explored returns whether a tile of the level has been explored.
+func (m *Memory) explored(level int32) func(pos swagger.DungeonsandtrollsPosition) bool {
+	mem := m.Levels[level]
+	return func(pos swagger.DungeonsandtrollsPosition) bool {
+		return mem != nil && mem.contains(pos) && mem.Explored[mem.index(pos)]
+	}
+}
+
+// resistances returns the monster resistances remembered from the deepest level.
+func (m *Memory) resistances() map[swagger.DungeonsandtrollsDamageType]float32 {
+	var resists map[swagger.DungeonsandtrollsDamageType]float32
+	deepest := int32(-1)
+	for n, level := range m.Levels {
+		if level.Resistances != nil && n > deepest {
+			resists, deepest = level.Resistances, n
+		}
+	}
+	return resists
+}
+
+// Recall returns the state with the current level completed from the memory:
+// remembered static objects on tiles the state has no object on
+// and monsters that are out of sight where we last saw them.
+func (m *Memory) Recall(state swagger.DungeonsandtrollsGameState) swagger.DungeonsandtrollsGameState {
+	if state.Map_ == nil {
+		return state
+	}
+
+	levels := make([]swagger.DungeonsandtrollsLevel, len(state.Map_.Levels))
+	copy(levels, state.Map_.Levels)
+	for i := range levels {
+		level := &levels[i]
+		mem := m.Levels[level.Level]
+		if level.Level != state.CurrentLevel || mem == nil {
+			continue
+		}
+
+		objects := map[swagger.DungeonsandtrollsPosition]int{}
+		level.Objects = append([]swagger.DungeonsandtrollsMapObjects(nil), level.Objects...)
+		for i, object := range level.Objects {
+			if object.Position != nil {
+				objects[*object.Position] = i
+			}
+		}
+
+		tiles := maps.Keys(mem.Tiles)
+		slices.Sort(tiles)
+		for _, index := range tiles {
+			tile := mem.Tiles[index]
+			if _, ok := objects[*tile.Position]; !ok {
+				objects[*tile.Position] = len(level.Objects)
+				level.Objects = append(level.Objects, tile)
+			}
+		}
+
+		ids := maps.Keys(mem.Monsters)
+		slices.Sort(ids)
+		for _, id := range ids {
+			seen := mem.Monsters[id]
+			if seen.Tick == state.Tick {
+				continue
+			}
+			log.Printf("Remembering %s (%s) on position %+v\n", seen.Monster.Name, id, seen.Position)
+			i, ok := objects[seen.Position]
+			if !ok {
+				position := seen.Position
+				i = len(level.Objects)
+				objects[position] = i
+				level.Objects = append(level.Objects, swagger.DungeonsandtrollsMapObjects{Position: &position})
+			}
+			object := &level.Objects[i]
+			object.Monsters = append(append([]swagger.DungeonsandtrollsMonster(nil), object.Monsters...), seen.Monster)
+		}
+	}
+
+	mapCopy := *state.Map_
+	mapCopy.Levels = levels
+	state.Map_ = &mapCopy
+	return state
+}
@@ -0,0 +1,126 @@
+package bot
+
+import (
+	"path/filepath"
+	"testing"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+)
+
+func TestMemory(t *testing.T) {
+	stairs := swagger.DungeonsandtrollsPosition{PositionX: 4}
+	goblin := swagger.DungeonsandtrollsPosition{PositionX: 3}
+	// A corridor of tiles 0 to 4 with the stairs at the end, we see only the tiles next to us
+	// and the server sends only the objects in sight.
+	state := func(tick int32, x int32, monster bool) swagger.DungeonsandtrollsGameState {
+		level := swagger.DungeonsandtrollsLevel{Width: 5, Height: 1}
+		for i := int32(0); i < 5; i++ {
+			pos := swagger.DungeonsandtrollsPosition{PositionX: i}
+			inSight := abs(int(i-x)) <= 1
+			level.PlayerMap = append(level.PlayerMap, swagger.DungeonsandtrollsPlayerSpecificMap{
+				Position:    &pos,
+				Distance:    int32(abs(int(i - x))),
+				LineOfSight: inSight,
+			})
+			if !inSight {
+				continue
+			}
+			if pos == stairs {
+				level.Objects = append(level.Objects, swagger.DungeonsandtrollsMapObjects{Position: &pos, IsStairs: true})
+			}
+			if pos == goblin && monster {
+				level.Objects = append(level.Objects, swagger.DungeonsandtrollsMapObjects{
+					Position: &pos,
+					Monsters: []swagger.DungeonsandtrollsMonster{{Id: "goblin", Name: "Goblin"}},
+				})
+			}
+		}
+		return swagger.DungeonsandtrollsGameState{
+			Tick:            tick,
+			CurrentPosition: &swagger.DungeonsandtrollsPosition{PositionX: x},
+			Map_:            &swagger.DungeonsandtrollsMap{Levels: []swagger.DungeonsandtrollsLevel{level}},
+		}
+	}
+	goblins := func(s swagger.DungeonsandtrollsGameState) int {
+		n := 0
+		for _, object := range s.Map_.Levels[0].Objects {
+			n += len(object.Monsters)
+		}
+		return n
+	}
+
+	type step struct {
+		tick    int32
+		x       int32
+		monster bool
+	}
+	tests := []struct {
+		name  string
+		steps []step
+		// save saves the memory and loads it back after the steps.
+		save bool
+		// query is the state the memory is recalled to.
+		query       step
+		wantGoblins int
+	}{
+		{
+			name:        "out of sight",
+			steps:       []step{{1, 3, true}, {2, 1, true}},
+			query:       step{2, 1, true},
+			wantGoblins: 1,
+		},
+		{
+			name:  "monster tile seen empty",
+			steps: []step{{1, 3, true}, {2, 1, true}, {3, 2, false}},
+			query: step{3, 2, false},
+		},
+		{
+			name:  "monster seen long ago",
+			steps: []step{{1, 3, true}, {monsterMemoryTicks + 2, 0, false}},
+			query: step{monsterMemoryTicks + 2, 0, false},
+		},
+		{
+			name:  "monster seen in a restarted game",
+			steps: []step{{50, 3, true}, {1, 0, false}},
+			query: step{1, 0, false},
+		},
+		{
+			name:  "saved and loaded",
+			steps: []step{{1, 3, true}, {2, 1, true}},
+			save:  true,
+			query: step{3, 0, false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memory := NewMemory()
+			for _, step := range tt.steps {
+				memory.Observe(state(step.tick, step.x, step.monster))
+			}
+			if tt.save {
+				path := filepath.Join(t.TempDir(), "memory.json")
+				if err := memory.Save(path); err != nil {
+					t.Fatal(err)
+				}
+				var err error
+				if memory, err = LoadMemory(path); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			s := state(tt.query.tick, tt.query.x, tt.query.monster)
+			objects := len(s.Map_.Levels[0].Objects)
+			memory.Observe(s)
+			recalled := memory.Recall(s)
+			if got := findStairs(&recalled); got == nil || *got != stairs {
+				t.Errorf("recalled stairs %+v, want %+v", got, stairs)
+			}
+			if got := goblins(recalled); got != tt.wantGoblins {
+				t.Errorf("recalled %d goblins, want %d", got, tt.wantGoblins)
+			}
+			if len(s.Map_.Levels[0].Objects) != objects {
+				t.Errorf("recall changed the observed state")
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package bot
+
+import (
+	"log"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+	"github.com/liennie/gdt/internal/grid"
+)
+
+func currentGrid(state *swagger.DungeonsandtrollsGameState) *grid.Grid {
+	for _, level := range state.Map_.Levels {
+		if level.Level == state.CurrentLevel {
+			return grid.New(level)
+		}
+	}
+	return nil
+}
+
+// moveTowards plans the cheapest path to goal and returns where to send the character this tick.
+// Without a usable map it leaves the pathfinding to the server.
+func moveTowards(state *swagger.DungeonsandtrollsGameState, goal swagger.DungeonsandtrollsPosition, cost grid.Cost) *swagger.DungeonsandtrollsPosition {
+	g := currentGrid(state)
+	if g == nil {
+		return &goal
+	}
+
+	path, ok := g.Path(*state.CurrentPosition, goal, cost)
+	if !ok {
+		log.Printf("No path to %+v\n", goal)
+		return &goal
+	}
+	log.Printf("Path to %+v takes %d turns with cost %.1f\n", goal, path.Turns(), path.Cost)
+
+	// Without a detour the server's own shortest path is as good as ours. A detour costing nothing
+	// on top of its steps is still a detour, the server would walk straight through what it avoids.
+	if shortest, ok := g.Path(*state.CurrentPosition, goal, nil); ok && shortest.Turns() == path.Turns() && path.Cost == float64(path.Turns()) {
+		return &goal
+	}
+
+	waypoint := path.Waypoint(*state.CurrentPosition)
+	log.Printf("Avoiding danger via %+v\n", waypoint)
+	return &waypoint
+}
@@ -0,0 +1,57 @@
+package bot
+
+import (
+	"testing"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+	"github.com/liennie/gdt/internal/grid"
+)
+
+func TestMoveTowards(t *testing.T) {
+	// An open room of 5x3 tiles, we go along the middle row from one end to the other.
+	state := &swagger.DungeonsandtrollsGameState{
+		CurrentPosition: &swagger.DungeonsandtrollsPosition{PositionY: 1},
+		Map_: &swagger.DungeonsandtrollsMap{Levels: []swagger.DungeonsandtrollsLevel{{
+			Width:  5,
+			Height: 3,
+		}}},
+	}
+	goal := swagger.DungeonsandtrollsPosition{PositionX: 4, PositionY: 1}
+	danger := swagger.DungeonsandtrollsPosition{PositionX: 2, PositionY: 1}
+
+	tests := []struct {
+		name string
+		cost grid.Cost
+		// wantGoal is whether the pathfinding is left to the server.
+		wantGoal bool
+	}{
+		{"no danger", nil, true},
+		{"danger away from the way", func(pos grid.Position) float64 {
+			if pos == (grid.Position{PositionX: 2}) {
+				return 100
+			}
+			return 0
+		}, true},
+		{"detour costing nothing extra", func(pos grid.Position) float64 {
+			if pos == danger {
+				return 100
+			}
+			return 0
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := moveTowards(state, goal, tt.cost)
+			if tt.wantGoal {
+				if got == nil || *got != goal {
+					t.Errorf("move %+v, want %+v", got, goal)
+				}
+				return
+			}
+			// The server walks straight to the waypoint, so it must not be behind the danger on the same row.
+			if got == nil || *got == goal || got.PositionY == danger.PositionY && got.PositionX >= danger.PositionX {
+				t.Errorf("move %+v, want a waypoint around %+v", got, danger)
+			}
+		})
+	}
+}
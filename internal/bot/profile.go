@@ -0,0 +1,140 @@
+package bot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+	"golang.org/x/exp/slices"
+)
+
+// DefaultProfile is the name of the profile used when none is picked.
+const DefaultProfile = "balanced"
+
+// Profile is a named build telling how to split attribute points between attributes.
+type Profile struct {
+	Name   string         `json:"name"`
+	Stages []ProfileStage `json:"stages"`
+}
+
+// ProfileStage applies from MinLevel, the deepest level reached, until the next stage.
+type ProfileStage struct {
+	MinLevel int32 `json:"minLevel,omitempty"`
+	// Weights are the ratios to split the points in, e.g. {"intelligence": 2, "constitution": 1}.
+	Weights swagger.DungeonsandtrollsAttributes `json:"weights"`
+}
+
+// Profiles are the built-in profiles.
+var Profiles = map[string]Profile{
+	"balanced": {
+		Name: "balanced",
+		Stages: []ProfileStage{{
+			Weights: swagger.DungeonsandtrollsAttributes{
+				Strength: 1, Dexterity: 1, Constitution: 1,
+				SlashResist: 1, PierceResist: 1, FireResist: 1,
+			},
+		}},
+	},
+	"fire-caster": {
+		Name: "fire-caster",
+		Stages: []ProfileStage{{
+			Weights: swagger.DungeonsandtrollsAttributes{
+				Intelligence: 3, Willpower: 1, Constitution: 1, FireResist: 1,
+			},
+		}},
+	},
+	"melee-tank": {
+		Name: "melee-tank",
+		Stages: []ProfileStage{{
+			Weights: swagger.DungeonsandtrollsAttributes{
+				Strength: 3, Constitution: 2, SlashResist: 1, PierceResist: 1,
+			},
+		}, {
+			MinLevel: 5,
+			Weights: swagger.DungeonsandtrollsAttributes{
+				Strength: 2, Constitution: 3, SlashResist: 1, PierceResist: 1, FireResist: 1,
+			},
+		}},
+	},
+	"hybrid": {
+		Name: "hybrid",
+		Stages: []ProfileStage{{
+			Weights: swagger.DungeonsandtrollsAttributes{
+				Strength: 2, Intelligence: 2, Dexterity: 1, Constitution: 1,
+			},
+		}},
+	},
+}
+
+// LoadProfiles returns the built-in profiles together with the profiles from a JSON file
+// holding a list of profiles. Profiles from the file replace built-in profiles of the same name.
+func LoadProfiles(path string) (map[string]Profile, error) {
+	profiles := map[string]Profile{}
+	for name, profile := range Profiles {
+		profiles[name] = profile
+	}
+	if path == "" {
+		return profiles, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read profiles: %w", err)
+	}
+	var loaded []Profile
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return nil, fmt.Errorf("parse profiles %s: %w", path, err)
+	}
+
+	for _, profile := range loaded {
+		if err := profile.validate(); err != nil {
+			return nil, fmt.Errorf("profile %q: %w", profile.Name, err)
+		}
+		profile.Stages = slices.Clone(profile.Stages)
+		slices.SortStableFunc(profile.Stages, func(a, b ProfileStage) int {
+			return int(a.MinLevel - b.MinLevel)
+		})
+		profiles[profile.Name] = profile
+	}
+	return profiles, nil
+}
+
+func (p Profile) validate() error {
+	if p.Name == "" {
+		return errors.New("missing name")
+	}
+	if len(p.Stages) == 0 {
+		return errors.New("no stages")
+	}
+	for _, stage := range p.Stages {
+		if stage.Weights.Constant != 0 {
+			return fmt.Errorf("stage %d: constant can't be allocated", stage.MinLevel)
+		}
+		for _, weight := range attributeValues(&stage.Weights) {
+			if weight < 0 {
+				return fmt.Errorf("stage %d: negative weight", stage.MinLevel)
+			}
+		}
+		if attributeSum(&stage.Weights) == 0 {
+			return fmt.Errorf("stage %d: no weights", stage.MinLevel)
+		}
+	}
+	return nil
+}
+
+// weights returns the weights of the last stage applying at the level.
+func (p Profile) weights(level int32) *swagger.DungeonsandtrollsAttributes {
+	if len(p.Stages) == 0 {
+		return Profiles[DefaultProfile].weights(level)
+	}
+
+	weights := &p.Stages[0].Weights
+	for i := range p.Stages {
+		if p.Stages[i].MinLevel <= level {
+			weights = &p.Stages[i].Weights
+		}
+	}
+	return weights
+}
@@ -0,0 +1,66 @@
+package bot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+)
+
+func TestLoadProfiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	err := os.WriteFile(path, []byte(`[{
+		"name": "glass-cannon",
+		"stages": [
+			{"minLevel": 5, "weights": {"intelligence": 1, "constitution": 1}},
+			{"weights": {"intelligence": 1}}
+		]
+	}]`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	profiles, err := LoadProfiles(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := profiles[DefaultProfile]; !ok {
+		t.Errorf("missing built-in profile %q", DefaultProfile)
+	}
+	profile, ok := profiles["glass-cannon"]
+	if !ok {
+		t.Fatal("missing loaded profile")
+	}
+
+	tests := []struct {
+		level int32
+		want  swagger.DungeonsandtrollsAttributes
+	}{
+		{0, swagger.DungeonsandtrollsAttributes{Intelligence: 1}},
+		{4, swagger.DungeonsandtrollsAttributes{Intelligence: 1}},
+		{5, swagger.DungeonsandtrollsAttributes{Intelligence: 1, Constitution: 1}},
+	}
+	for _, tt := range tests {
+		if got := *profile.weights(tt.level); got != tt.want {
+			t.Errorf("level %d: got %+v, want %+v", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestLoadProfilesInvalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"no name":         `[{"stages": [{"weights": {"strength": 1}}]}]`,
+		"no stages":       `[{"name": "empty"}]`,
+		"no weights":      `[{"name": "zero", "stages": [{"weights": {}}]}]`,
+		"negative weight": `[{"name": "negative", "stages": [{"weights": {"strength": -1, "dexterity": 2}}]}]`,
+	} {
+		path := filepath.Join(t.TempDir(), "profiles.json")
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadProfiles(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package bot
+
+import (
+	"log"
+	"math"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+)
+
+const (
+	// rotationHorizon is how many ticks ahead the rotation planner looks.
+	rotationHorizon = 3
+	// rotationDiscount makes value gained sooner worth more than value gained later.
+	rotationDiscount = 0.9
+	// rotationKillBonus is the value of killing the target on top of the damage dealt.
+	rotationKillBonus = 10
+)
+
+// rotation is the state of a fight changed by the planned skills.
+type rotation struct {
+	caster  swagger.DungeonsandtrollsAttributes
+	target  swagger.DungeonsandtrollsAttributes
+	stunned int
+
+	tick int32
+	// casts are the ticks the skills were last cast at in the plan.
+	casts map[string]int32
+}
+
+// schedule tells when skills can be cast. Nil functions put no limits.
+type schedule struct {
+	// ready reports whether the skill can be cast at the tick, apart from its cost.
+	// At the current tick it checks the cost too.
+	ready func(skill *swagger.DungeonsandtrollsSkill, tick int32) bool
+	// cooldown returns the number of ticks between two casts of the skill.
+	cooldown func(skill *swagger.DungeonsandtrollsSkill) int32
+}
+
+// planRotation returns the first skill of the sequence of skills with the best value against the target
+// within rotationHorizon ticks. The value counts the damage dealt, the life drained by target effects,
+// the damage prevented by stunning the target and killing it. Debuffs of the target and buffs of the caster pay off through the following skills.
+// It returns nil if no skill in range does anything to the target.
+func planRotation(state *swagger.DungeonsandtrollsGameState, target *target, dist int, sched schedule) *swagger.DungeonsandtrollsSkill {
+	skills := rotationSkills(state, dist)
+	if len(skills) == 0 || target.monster.Attributes == nil {
+		return nil
+	}
+
+	monsterDamage := float32(0)
+	for _, attack := range monsterAttacks(target.monster, state.Character.Attributes) {
+		monsterDamage += attack.damage
+	}
+
+	start := rotation{
+		caster: *state.Character.Attributes,
+		target: *target.monster.Attributes,
+		tick:   state.Tick,
+	}
+	skill, value := start.plan(skills, monsterDamage, rotationHorizon, sched)
+	if skill != nil {
+		log.Printf("Planned rotation starting with %s with value %.2f\n", skill.Name, value)
+	}
+	return skill
+}
+
+// rotationSkills lists the equipped skills harming a target dist tiles away and buffs of the caster.
+func rotationSkills(state *swagger.DungeonsandtrollsGameState, dist int) []swagger.DungeonsandtrollsSkill {
+	var skills []swagger.DungeonsandtrollsSkill
+	for _, item := range state.Character.Equip {
+		for _, skill := range item.Skills {
+			if skill.Target == nil || skill.Cost == nil || (skill.Flags != nil && skill.Flags.Passive) {
+				continue
+			}
+
+			switch *skill.Target {
+			case swagger.CHARACTER_SkillTarget:
+				if skill.Range_ == nil || int(math.Trunc(float64(calculateAttributesValue(state.Character.Attributes, skill.Range_)))) < dist {
+					continue
+				}
+				if harmsTarget(&skill, state.Character.Attributes) {
+					skills = append(skills, skill)
+				}
+			case swagger.NONE_SkillTarget:
+				if isBuff(&skill) {
+					skills = append(skills, skill)
+				}
+			}
+		}
+	}
+	return skills
+}
+
+// harmsTarget reports whether the skill damages, stuns or weakens its target without raising any of its attributes.
+func harmsTarget(skill *swagger.DungeonsandtrollsSkill, caster *swagger.DungeonsandtrollsAttributes) bool {
+	harm := skill.DamageAmount != nil
+	if effects := skill.TargetEffects; effects != nil {
+		if effects.Flags != nil && effects.Flags.Stun {
+			harm = true
+		}
+		change := applySkillAttributes(&swagger.DungeonsandtrollsAttributes{}, effects.Attributes, caster)
+		for _, value := range attributeValues(change) {
+			if value > 0 {
+				return false
+			}
+			harm = harm || value < 0
+		}
+	}
+	return harm
+}
+
+// isBuff reports whether the skill raises attributes of the caster other than its resources.
+func isBuff(skill *swagger.DungeonsandtrollsSkill) bool {
+	if skill.CasterEffects == nil || skill.CasterEffects.Attributes == nil {
+		return false
+	}
+	effects := *skill.CasterEffects.Attributes
+	effects.Life, effects.Stamina, effects.Mana = nil, nil, nil
+	return effects != swagger.DungeonsandtrollsSkillAttributes{}
+}
+
+// plan returns the best skill to cast next and the value of the best sequence starting with it.
+// Skills are limited by the resources left and by the schedule, including the cooldowns of skills cast in the plan.
+func (r rotation) plan(skills []swagger.DungeonsandtrollsSkill, monsterDamage float32, depth int, sched schedule) (*swagger.DungeonsandtrollsSkill, float32) {
+	if depth == 0 || r.target.Life <= 0 {
+		return nil, 0
+	}
+
+	var best *swagger.DungeonsandtrollsSkill
+	bestValue := float32(0)
+	for i := range skills {
+		skill := &skills[i]
+		if !haveRequiredAttirbutes(&r.caster, skill.Cost) || !r.castable(skill, sched) {
+			continue
+		}
+
+		next, value := r.cast(skill, monsterDamage)
+		_, later := next.plan(skills, monsterDamage, depth-1, sched)
+		value += later * rotationDiscount
+		if value > bestValue {
+			best, bestValue = skill, value
+		}
+	}
+	return best, bestValue
+}
+
+func (r rotation) castable(skill *swagger.DungeonsandtrollsSkill, sched schedule) bool {
+	if sched.ready != nil && !sched.ready(skill, r.tick) {
+		return false
+	}
+	if last, ok := r.casts[skill.Id]; ok && sched.cooldown != nil && r.tick-last < sched.cooldown(skill) {
+		return false
+	}
+	return true
+}
+
+// cast returns the state after casting the skill and the value gained this tick.
+func (r rotation) cast(skill *swagger.DungeonsandtrollsSkill, monsterDamage float32) (rotation, float32) {
+	caster := r.caster
+	next := r
+	next.caster = *subtractAttributes(&next.caster, skill.Cost)
+	next.tick++
+	next.casts = map[string]int32{skill.Id: r.tick}
+	for id, tick := range r.casts {
+		if id != skill.Id {
+			next.casts[id] = tick
+		}
+	}
+
+	value := float32(0)
+	if skill.Target != nil && *skill.Target == swagger.CHARACTER_SkillTarget {
+		damage := min(skillDamage(&caster, skill, &r.target), r.target.Life)
+		next.target.Life -= damage
+		value += damage
+
+		if skill.TargetEffects != nil {
+			life := next.target.Life
+			next.target = *applySkillAttributes(&next.target, skill.TargetEffects.Attributes, &caster)
+			value += life - max(next.target.Life, 0)
+			if skill.TargetEffects.Flags != nil && skill.TargetEffects.Flags.Stun {
+				duration := 1
+				if skill.Duration != nil {
+					duration = max(duration, int(calculateAttributesValue(&caster, skill.Duration)))
+				}
+				next.stunned = max(next.stunned, duration)
+			}
+		}
+		if next.target.Life <= 0 {
+			value += rotationKillBonus + monsterDamage
+		}
+	}
+	if skill.CasterEffects != nil {
+		next.caster = *applySkillAttributes(&next.caster, skill.CasterEffects.Attributes, &caster)
+	}
+
+	if next.stunned > 0 && next.target.Life > 0 {
+		value += monsterDamage
+		next.stunned--
+	}
+	return next, value
+}
@@ -0,0 +1,131 @@
+package bot
+
+import (
+	"testing"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+)
+
+func TestPlanRotation(t *testing.T) {
+	slash := swagger.SLASH_DungeonsandtrollsDamageType
+	character := swagger.CHARACTER_SkillTarget
+	skill := func(id string, damage float32, effects *swagger.DungeonsandtrollsSkillEffect) swagger.DungeonsandtrollsSkill {
+		return swagger.DungeonsandtrollsSkill{
+			Id:            id,
+			Name:          id,
+			Target:        &character,
+			Cost:          &swagger.DungeonsandtrollsAttributes{Mana: 5},
+			Range_:        &swagger.DungeonsandtrollsAttributes{Constant: 1},
+			DamageAmount:  &swagger.DungeonsandtrollsAttributes{Constant: damage},
+			DamageType:    &slash,
+			TargetEffects: effects,
+			Flags:         &swagger.DungeonsandtrollsSkillGenericFlags{},
+		}
+	}
+	sword := testItem("sword", swagger.MAIN_HAND_DungeonsandtrollsItemType, 0, swagger.DungeonsandtrollsAttributes{}, swagger.DungeonsandtrollsAttributes{})
+	sword.Skills = []swagger.DungeonsandtrollsSkill{
+		skill("strike", 10, nil),
+		skill("weaken", 2, &swagger.DungeonsandtrollsSkillEffect{
+			Attributes: &swagger.DungeonsandtrollsSkillAttributes{
+				SlashResist: &swagger.DungeonsandtrollsAttributes{Constant: -10},
+			},
+		}),
+	}
+
+	tests := []struct {
+		name   string
+		mana   float32
+		target swagger.DungeonsandtrollsAttributes
+		// strikeCooldown is the number of ticks between two strikes, no limit if zero.
+		strikeCooldown int32
+		want           string
+	}{
+		{"debuff a resistant target first", 15, swagger.DungeonsandtrollsAttributes{Life: 100, SlashResist: 10}, 0, "weaken"},
+		{"finish a wounded target", 15, swagger.DungeonsandtrollsAttributes{Life: 4, SlashResist: 10}, 0, "strike"},
+		{"no mana for the follow up", 5, swagger.DungeonsandtrollsAttributes{Life: 100, SlashResist: 10}, 0, "strike"},
+		{"no mana at all", 0, swagger.DungeonsandtrollsAttributes{Life: 100}, 0, ""},
+		{"strike only every other tick", 15, swagger.DungeonsandtrollsAttributes{Life: 100, SlashResist: 10}, 2, "strike"},
+	}
+	for _, tt := range tests {
+		state := &swagger.DungeonsandtrollsGameState{
+			Character: &swagger.DungeonsandtrollsCharacter{
+				Attributes: &swagger.DungeonsandtrollsAttributes{Mana: tt.mana},
+				Equip:      []swagger.DungeonsandtrollsItem{sword},
+			},
+		}
+		target := &target{monster: swagger.DungeonsandtrollsMonster{Attributes: &tt.target}}
+		var sched schedule
+		if tt.strikeCooldown > 0 {
+			sched.cooldown = func(skill *swagger.DungeonsandtrollsSkill) int32 {
+				if skill.Id == "strike" {
+					return tt.strikeCooldown
+				}
+				return 1
+			}
+		}
+
+		got := ""
+		if skill := planRotation(state, target, 1, sched); skill != nil {
+			got = skill.Id
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPlanRotationTargetEffects(t *testing.T) {
+	slash := swagger.SLASH_DungeonsandtrollsDamageType
+	character := swagger.CHARACTER_SkillTarget
+	skill := func(id string, damage, life float32) swagger.DungeonsandtrollsSkill {
+		s := swagger.DungeonsandtrollsSkill{
+			Id:     id,
+			Name:   id,
+			Target: &character,
+			Cost:   &swagger.DungeonsandtrollsAttributes{Mana: 1},
+			Range_: &swagger.DungeonsandtrollsAttributes{Constant: 1},
+			Flags:  &swagger.DungeonsandtrollsSkillGenericFlags{},
+		}
+		if damage > 0 {
+			s.DamageAmount = &swagger.DungeonsandtrollsAttributes{Constant: damage}
+			s.DamageType = &slash
+		}
+		if life != 0 {
+			s.TargetEffects = &swagger.DungeonsandtrollsSkillEffect{
+				Attributes: &swagger.DungeonsandtrollsSkillAttributes{Life: &swagger.DungeonsandtrollsAttributes{Constant: life}},
+			}
+		}
+		return s
+	}
+
+	tests := []struct {
+		name   string
+		skills []swagger.DungeonsandtrollsSkill
+		want   string
+	}{
+		{"healing the target is never planned", []swagger.DungeonsandtrollsSkill{skill("mend", 0, 50)}, ""},
+		{"healing does not pay off later", []swagger.DungeonsandtrollsSkill{skill("strike", 5, 0), skill("mend", 0, 50)}, "strike"},
+		{"life drained counts as damage", []swagger.DungeonsandtrollsSkill{skill("strike", 5, 0), skill("drain", 0, -8)}, "drain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			staff := testItem("staff", swagger.MAIN_HAND_DungeonsandtrollsItemType, 0, swagger.DungeonsandtrollsAttributes{}, swagger.DungeonsandtrollsAttributes{})
+			staff.Skills = tt.skills
+			state := &swagger.DungeonsandtrollsGameState{
+				Character: &swagger.DungeonsandtrollsCharacter{
+					Attributes: &swagger.DungeonsandtrollsAttributes{Mana: 10},
+					Equip:      []swagger.DungeonsandtrollsItem{staff},
+				},
+			}
+			target := &target{monster: swagger.DungeonsandtrollsMonster{Attributes: &swagger.DungeonsandtrollsAttributes{Life: 100}}}
+
+			got := ""
+			if skill := planRotation(state, target, 1, schedule{}); skill != nil {
+				got = skill.Id
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
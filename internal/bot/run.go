@@ -0,0 +1,313 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+)
+
+func (d Default) run(state swagger.DungeonsandtrollsGameState) *swagger.DungeonsandtrollsCommandsBatch {
+	log.Println("Score:", state.Score)
+	log.Println("Character.Money", state.Character.Money)
+	// LogStruct(reflect.ValueOf(state.Character.Equip), "Character.Equip")
+	log.Println()
+	LogStruct(reflect.ValueOf(state.Character.Attributes), "Character.Attributes")
+	log.Println()
+	log.Println("CurrentLevel:", state.CurrentLevel)
+	log.Println("CurrentPosition.PositionX:", state.CurrentPosition.PositionX)
+	log.Println("CurrentPosition.PositionY:", state.CurrentPosition.PositionY)
+
+	_, haveMainHand := loadout(state.Character.Equip).get(swagger.MAIN_HAND_DungeonsandtrollsItemType)
+
+	if state.Character.SkillPoints > 1.5 {
+		log.Println("Spending attribute points ...")
+		return &swagger.DungeonsandtrollsCommandsBatch{
+			AssignSkillPoints: d.spendAttributePoints(&state),
+			Yell: &swagger.DungeonsandtrollsMessage{
+				Text: "Assigning skill points.",
+			},
+		}
+	}
+
+	if state.Character.Coordinates.Level == 0 {
+		log.Println("Looking for items to buy ...")
+		items := d.shop(&state, d.damageTypes(&state))
+		if len(items) > 0 {
+			itemIds := make([]string, len(items))
+			for i := range items {
+				itemIds[i] = items[i].Id
+			}
+
+			return &swagger.DungeonsandtrollsCommandsBatch{
+				Buy: &swagger.DungeonsandtrollsIdentifiers{Ids: itemIds},
+				Yell: &swagger.DungeonsandtrollsMessage{
+					Text: "Buying swag.",
+				},
+			}
+		} else if !haveMainHand {
+			log.Println("ERROR: Found no item to buy!")
+			return nil
+		}
+	}
+
+	stairsCoords := findStairs(&state)
+
+	var monster *swagger.DungeonsandtrollsMapObjects
+	var targetMonster swagger.DungeonsandtrollsMonster
+	target := findTarget(&state, chooseAttackSkill(&state, nil, d.damageTypes(&state)), stairsCoords)
+	if target != nil {
+		monster = target.object
+		targetMonster = target.monster
+	}
+
+	attackSkill := chooseAttackSkill(&state, target, nil)
+
+	if state.Character.Attributes.Life < state.Character.MaxAttributes.Life &&
+		(state.Character.Attributes.Life/state.Character.MaxAttributes.Life) < (state.Character.Attributes.Stamina/state.Character.MaxAttributes.Stamina) &&
+		(state.Character.Attributes.Life/state.Character.MaxAttributes.Life) < (state.Character.Attributes.Mana/state.Character.MaxAttributes.Stamina) &&
+		state.Character.LastDamageTaken > 2 &&
+		(monster == nil || distance(*state.CurrentPosition, *monster.Position) > 6) {
+
+		var skill *swagger.DungeonsandtrollsSkill
+
+		for _, equip := range state.Character.Equip {
+			for _, equipSkill := range equip.Skills {
+				equipSkill := equipSkill
+
+				if d.ready(&state, &equipSkill) &&
+					equipSkill.TargetEffects != nil &&
+					equipSkill.TargetEffects.Attributes != nil &&
+					equipSkill.TargetEffects.Attributes.Life != nil &&
+					calculateAttributesValue(state.Character.Attributes, equipSkill.TargetEffects.Attributes.Life) > 0 {
+
+					skill = &equipSkill
+					break
+				}
+			}
+		}
+		if skill != nil {
+			log.Println("Healing")
+			return &swagger.DungeonsandtrollsCommandsBatch{
+				Skill: &swagger.DungeonsandtrollsSkillUse{
+					SkillId:  skill.Id,
+					TargetId: state.Character.Id,
+				},
+				Yell: &swagger.DungeonsandtrollsMessage{
+					Text: "<color=\"green\">Healing.</color>",
+				},
+			}
+		}
+	}
+
+	if (state.Character.Attributes.Stamina < state.Character.MaxAttributes.Stamina &&
+		state.Character.LastDamageTaken > 2 &&
+		(monster == nil || attackSkill == nil || distance(*state.CurrentPosition, *monster.Position) > skillReach(state.Character.Attributes, attackSkill)+1)) ||
+		(attackSkill != nil && !haveRequiredAttirbutes(state.Character.Attributes, attackSkill.Cost) && state.Character.LastDamageTaken > 2) ||
+		(state.Character.Attributes.Mana < state.Character.MaxAttributes.Mana &&
+			state.Character.LastDamageTaken > 2 &&
+			(monster == nil || attackSkill == nil || distance(*state.CurrentPosition, *monster.Position) > skillReach(state.Character.Attributes, attackSkill)+1)) ||
+		(attackSkill != nil && !haveRequiredAttirbutes(state.Character.Attributes, attackSkill.Cost) && state.Character.LastDamageTaken > 2) {
+
+		var skill *swagger.DungeonsandtrollsSkill
+
+		for _, equip := range state.Character.Equip {
+			for _, equipSkill := range equip.Skills {
+				equipSkill := equipSkill
+
+				if d.ready(&state, &equipSkill) &&
+					!equipSkill.Flags.Passive &&
+					equipSkill.CasterEffects != nil &&
+					equipSkill.CasterEffects.Attributes != nil &&
+					equipSkill.CasterEffects.Attributes.Stamina != nil &&
+					calculateAttributesValue(state.Character.Attributes, equipSkill.CasterEffects.Attributes.Stamina) > 0 {
+
+					skill = &equipSkill
+					break
+				}
+			}
+		}
+		if skill != nil {
+			log.Println("Resting")
+			return &swagger.DungeonsandtrollsCommandsBatch{
+				Skill: &swagger.DungeonsandtrollsSkillUse{
+					SkillId: skill.Id,
+				},
+				Yell: &swagger.DungeonsandtrollsMessage{
+					Text: "<color=#00FFFF>Resting.</color>",
+				},
+			}
+		}
+	}
+
+	if monster != nil {
+		if attackSkill != nil {
+			log.Println("Let's fight!")
+			dist := mapDistance(*monster.Position, state)
+			var area *areaHit
+			inRange := false
+			if isAreaSkill(attackSkill) {
+				area = bestArea(&state, attackSkill, target)
+				inRange = area != nil
+			} else {
+				inRange = dist <= skillReach(state.Character.Attributes, attackSkill) && lineOfSight(*monster.Position, state)
+			}
+			if inRange {
+				log.Println("Attacking ...")
+				ready := func(skill *swagger.DungeonsandtrollsSkill) bool {
+					return d.ready(&state, skill)
+				}
+				// An area skill hitting several monsters beats any single target rotation.
+				// The area may be in reach of a target we can't see, single target skills need it in sight.
+				if (area == nil || area.monsters < 2 || !ready(attackSkill)) && lineOfSight(*monster.Position, state) {
+					if skill := planRotation(&state, target, dist, d.schedule(&state)); skill != nil {
+						attackSkill = skill
+						area = nil
+					}
+				}
+				if !ready(attackSkill) {
+					log.Println("Waiting for", attackSkill.Name)
+					return &swagger.DungeonsandtrollsCommandsBatch{
+						Yell: &swagger.DungeonsandtrollsMessage{
+							Text: fmt.Sprintf("Waiting for %s.", attackSkill.Name),
+						},
+					}
+				}
+				log.Println("Picked skill:", attackSkill.Name, "with target type:", *attackSkill.Target)
+				damage := skillDamage(state.Character.Attributes, attackSkill, targetMonster.Attributes)
+				log.Println("Estimated damage:", damage)
+
+				if area != nil {
+					log.Printf("Hitting %d monsters for %.2f damage\n", area.monsters, area.damage)
+				}
+
+				if *attackSkill.Target == swagger.POSITION_SkillTarget && area != nil {
+					return &swagger.DungeonsandtrollsCommandsBatch{
+						Skill: &swagger.DungeonsandtrollsSkillUse{
+							SkillId:  attackSkill.Id,
+							Position: &area.position,
+						},
+						Yell: &swagger.DungeonsandtrollsMessage{
+							Text: fmt.Sprintf("<color=\"red\">%s!</color>", attackSkill.Name),
+						},
+					}
+				}
+				if *attackSkill.Target == swagger.CHARACTER_SkillTarget {
+					return &swagger.DungeonsandtrollsCommandsBatch{
+						Skill: &swagger.DungeonsandtrollsSkillUse{
+							SkillId:  attackSkill.Id,
+							TargetId: targetMonster.Id,
+						},
+						Yell: &swagger.DungeonsandtrollsMessage{
+							Text: fmt.Sprintf("<color=\"red\">%s!</color>", attackSkill.Name),
+						},
+					}
+				}
+				return &swagger.DungeonsandtrollsCommandsBatch{
+					Skill: &swagger.DungeonsandtrollsSkillUse{
+						SkillId: attackSkill.Id,
+					},
+					Yell: &swagger.DungeonsandtrollsMessage{
+						Text: fmt.Sprintf("<color=\"red\">%s!</color>", attackSkill.Name),
+					},
+				}
+			} else {
+				return &swagger.DungeonsandtrollsCommandsBatch{
+					Move: moveTowards(&state, *monster.Position, d.moveCost(&state, monster)),
+					Yell: &swagger.DungeonsandtrollsMessage{
+						Text: "<color=\"yellow\">Let's fight!</color>",
+					},
+				}
+			}
+		} else {
+			log.Println("No skill. Moving towards spawn ...")
+			spawn := findSpawn(&state)
+			if spawn != nil {
+				spawn = moveTowards(&state, *spawn, d.moveCost(&state, nil))
+			}
+			return &swagger.DungeonsandtrollsCommandsBatch{
+				Move: spawn,
+				Yell: &swagger.DungeonsandtrollsMessage{
+					Text: "<color=\"purple\">Running away!</color>",
+				},
+			}
+		}
+	}
+
+	if loot := findLoot(&state, d.damageTypes(&state)); loot != nil {
+		if loot.position == *state.CurrentPosition {
+			log.Println("Picking up", loot.item.Name)
+			return &swagger.DungeonsandtrollsCommandsBatch{
+				PickUp: &swagger.DungeonsandtrollsIdentifier{Id: loot.item.Id},
+				Yell: &swagger.DungeonsandtrollsMessage{
+					Text: fmt.Sprintf("Mine, %s!", loot.item.Name),
+				},
+			}
+		}
+
+		log.Println("Moving towards loot ...")
+		return &swagger.DungeonsandtrollsCommandsBatch{
+			Move: moveTowards(&state, loot.position, d.moveCost(&state, nil)),
+			Yell: &swagger.DungeonsandtrollsMessage{
+				Text: "<color=\"yellow\">Ooh, shiny.</color>",
+			},
+		}
+	}
+
+	log.Println("No monsters. Let's find stairs ...")
+
+	if stairsCoords == nil {
+		if frontier := d.explore(&state); frontier != nil {
+			log.Println("Can't find stairs, exploring ...")
+			return &swagger.DungeonsandtrollsCommandsBatch{
+				Move: moveTowards(&state, *frontier, d.moveCost(&state, nil)),
+				Yell: &swagger.DungeonsandtrollsMessage{
+					Text: "<color=\"yellow\">Where are the stairs?</color>",
+				},
+			}
+		}
+
+		log.Println("Can't find stairs")
+		return &swagger.DungeonsandtrollsCommandsBatch{
+			Yell: &swagger.DungeonsandtrollsMessage{
+				Text: "Where are the stairs? I can't find them!",
+			},
+		}
+	}
+
+	if distance(*state.CurrentPosition, *stairsCoords) <= 1 && state.CurrentLevel != 0 {
+		players := playersOnCurrentLevel(state)
+
+		maxDist := 0
+		var maxPlayer swagger.DungeonsandtrollsCharacter
+		for _, player := range players {
+			if player.Id == state.Character.Id {
+				continue
+			}
+
+			dist := distance(*stairsCoords, coords2pos(*player.Coordinates))
+			if dist > maxDist {
+				maxDist = dist
+				maxPlayer = player
+			}
+		}
+
+		if maxDist > 1 {
+			return &swagger.DungeonsandtrollsCommandsBatch{
+				Yell: &swagger.DungeonsandtrollsMessage{
+					Text: fmt.Sprintf("Hurry up, %s!", maxPlayer.Name),
+				},
+				Move: state.CurrentPosition,
+			}
+		}
+	}
+
+	log.Println("Moving towards stairs ...")
+	return &swagger.DungeonsandtrollsCommandsBatch{
+		Move: moveTowards(&state, *stairsCoords, d.moveCost(&state, nil)),
+		Yell: &swagger.DungeonsandtrollsMessage{
+			Text: "<color=\"yellow\">Let's go.</color>",
+		},
+	}
+}
@@ -0,0 +1,94 @@
+package bot
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+)
+
+var update = flag.Bool("update", false, "update the expected commands in testdata")
+
+// Each case is a directory in testdata/run with a captured state.json
+// and the command.json the default strategy is expected to return for it.
+// States can be taken from a session recorded with -record.
+// Run the tests with -update after an intended change of behavior.
+var runCases = []struct {
+	name string
+	desc string
+}{
+	{"assign_skill_points", "unspent skill points are assigned first"},
+	{"buy", "without a main hand item on level 0 we go shopping"},
+	{"nothing_to_buy", "an empty shop leaves us with nothing to do"},
+	{"upgrade", "back on level 0 with money we replace worse items"},
+	{"no_upgrade", "without a strict improvement we buy nothing and move on"},
+	{"rest", "out of combat with missing stamina we rest"},
+	{"heal", "low on life and away from monsters we heal"},
+	{"move_to_monster", "a monster out of range is approached"},
+	{"attack", "a monster in range is attacked"},
+	{"attack_wounded", "a wounded monster in range is finished off before a closer healthy one"},
+	{"attack_fire_resistant", "fire resistant monsters are still attacked with fire when there is nothing else"},
+	{"attack_area", "monsters next to each other get hit by an area skill"},
+	{"attack_area_out_of_sight", "a monster out of sight is hit by an area skill, not a single target one"},
+	{"attack_resisted_damage_type", "a skill the target does not resist beats a stronger resisted one"},
+	{"run_away", "without a usable attack skill we run to spawn"},
+	{"move_to_stairs", "with no monsters around we head to the stairs"},
+	{"stairs_unknown", "without known stairs and nothing left to explore we just complain"},
+	{"explore", "without known stairs we explore the tiles out of sight"},
+	{"move_to_loot", "without monsters we detour to items improving the loadout"},
+	{"pick_up_loot", "standing on a worthwhile item we pick it up"},
+	{"wait_for_party", "at the stairs we wait for party members lagging behind"},
+}
+
+func TestRun(t *testing.T) {
+	if !testing.Verbose() {
+		log.SetOutput(io.Discard)
+		defer log.SetOutput(os.Stderr)
+	}
+
+	for _, tc := range runCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := filepath.Join("testdata", "run", tc.name)
+
+			data, err := os.ReadFile(filepath.Join(dir, "state.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var state swagger.DungeonsandtrollsGameState
+			if err := json.Unmarshal(data, &state); err != nil {
+				t.Fatal(err)
+			}
+
+			var buf bytes.Buffer
+			enc := json.NewEncoder(&buf)
+			enc.SetEscapeHTML(false)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(Default{}.Decide(state)); err != nil {
+				t.Fatal(err)
+			}
+			got := buf.Bytes()
+
+			path := filepath.Join(dir, "command.json")
+			if *update {
+				if err := os.WriteFile(path, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s\ngot:\n%s\nwant:\n%s", tc.desc, got, want)
+			}
+		})
+	}
+}
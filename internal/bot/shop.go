@@ -0,0 +1,219 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	shopDamageWeight = 20
+	shopRestWeight   = 0.02
+	shopResistWeight = 0.05
+	shopRangeWeight  = 0.5
+	shopBeamWidth    = 5000
+)
+
+// shop picks the items to buy to get the best loadout we can afford, replacing the equipped items.
+// It returns nil unless the loadout is a strict improvement with an attack skill of the given damage types.
+func shop(state *swagger.DungeonsandtrollsGameState, types []swagger.DungeonsandtrollsDamageType) []swagger.DungeonsandtrollsItem {
+	owned := loadout(state.Character.Equip)
+	base := baseAttributes(state)
+
+	optimizer := loadoutOptimizer{
+		items:     state.ShopItems,
+		owned:     owned,
+		slots:     itemSlots,
+		budget:    int(state.Character.Money),
+		beamWidth: shopBeamWidth,
+		score:     shopScore(types),
+	}
+
+	best := optimizer.best(base)
+	var buy loadout
+	for _, item := range best {
+		if equipped, ok := owned.get(*item.Slot); !ok || equipped.Id != item.Id {
+			buy = append(buy, item)
+		}
+	}
+	if len(buy) == 0 {
+		return nil
+	}
+	if err := best.validate(base, optimizer.budget); err != nil {
+		log.Println("ERROR: Rejected loadout:", err)
+		return nil
+	}
+
+	attrs := best.attributes(base)
+	if skill, _ := loadoutDamage(best, attrs, types); skill == nil {
+		return nil
+	}
+	value := optimizer.score(best, attrs)
+	current := optimizer.score(owned, owned.attributes(base))
+	if value <= current {
+		return nil
+	}
+	log.Printf("Upgrading loadout for %d, score %.2f -> %.2f\n", buy.cost(), current, value)
+	return buy
+}
+
+// ShopCache remembers the last result of shop, so the loadout search runs only
+// when the money, the equip, the shop or our attributes other than regenerating resources change.
+type ShopCache struct {
+	key   string
+	items []swagger.DungeonsandtrollsItem
+}
+
+func NewShopCache() *ShopCache {
+	return &ShopCache{}
+}
+
+// shop is shop with the result cached if there is a ShopCache.
+// The loadout is searched with full life, mana and stamina, which regenerate anyway.
+func (d Default) shop(state *swagger.DungeonsandtrollsGameState, types []swagger.DungeonsandtrollsDamageType) []swagger.DungeonsandtrollsItem {
+	character := *state.Character
+	character.Attributes = restedAttributes(state.Character)
+	rested := *state
+	rested.Character = &character
+	state = &rested
+
+	if d.ShopCache == nil {
+		return shop(state, types)
+	}
+
+	ids := func(items []swagger.DungeonsandtrollsItem) []string {
+		res := make([]string, len(items))
+		for i := range items {
+			res[i] = fmt.Sprintf("%s:%d", items[i].Id, items[i].Price)
+		}
+		return res
+	}
+	key := fmt.Sprintf("%d|%v|%v|%v|%+v", state.Character.Money, ids(state.Character.Equip), ids(state.ShopItems), types, *state.Character.Attributes)
+	if key != d.ShopCache.key {
+		d.ShopCache.key = key
+		d.ShopCache.items = shop(state, types)
+	}
+	return d.ShopCache.items
+}
+
+// restedAttributes returns the attributes of the character with life, mana and stamina at their maximum.
+func restedAttributes(character *swagger.DungeonsandtrollsCharacter) *swagger.DungeonsandtrollsAttributes {
+	attrs := *character.Attributes
+	if full := character.MaxAttributes; full != nil {
+		attrs.Life = full.Life
+		attrs.Mana = full.Mana
+		attrs.Stamina = full.Stamina
+	}
+	return &attrs
+}
+
+// shopScore values damage of each of the damage types, the range of the skill dealing it,
+// the best rest and heal skills and resistances of the items.
+func shopScore(types []swagger.DungeonsandtrollsDamageType) loadoutScore {
+	return func(items loadout, attrs *swagger.DungeonsandtrollsAttributes) float32 {
+		value := float32(0)
+
+		for _, damageType := range types {
+			skill, damage := loadoutDamage(items, attrs, []swagger.DungeonsandtrollsDamageType{damageType})
+			if skill == nil {
+				continue
+			}
+			value += 1 + damage*damage*damage*shopDamageWeight
+			value += float32(skillReach(attrs, skill)) * shopRangeWeight
+		}
+
+		rest := float32(0)
+		patch := float32(0)
+		for i := range items {
+			_, itemRest := getItemRest(&items[i], attrs)
+			_, itemPatch := getItemPatch(&items[i], attrs)
+			rest = max(rest, itemRest)
+			patch = max(patch, itemPatch)
+
+			if resists := items[i].Attributes; resists != nil {
+				value += (1 + resists.SlashResist*0.1) * (1 + resists.PierceResist*0.75) * (1 + resists.FireResist*1) * shopResistWeight
+			}
+		}
+		value += 1 + rest*shopRestWeight
+		value += 1 + patch*shopRestWeight
+
+		return value
+	}
+}
+
+// loadoutDamage returns the best attack skill of the given damage types among the items.
+func loadoutDamage(items loadout, attrs *swagger.DungeonsandtrollsAttributes, types []swagger.DungeonsandtrollsDamageType) (*swagger.DungeonsandtrollsSkill, float32) {
+	var best *swagger.DungeonsandtrollsSkill
+	bestValue := float32(0)
+	for i := range items {
+		skill, value := getItemDamage(&items[i], attrs, types)
+		if value > bestValue {
+			best, bestValue = skill, value
+		}
+	}
+	return best, bestValue
+}
+
+func getItemDamage(item *swagger.DungeonsandtrollsItem, attrs *swagger.DungeonsandtrollsAttributes, types []swagger.DungeonsandtrollsDamageType) (*swagger.DungeonsandtrollsSkill, float32) {
+	var best *swagger.DungeonsandtrollsSkill
+	bestValue := float32(0)
+
+	for _, skill := range item.Skills {
+		skill := skill
+
+		if !isAttackSkill(&skill) || !slices.Contains(types, *skill.DamageType) {
+			continue
+		}
+
+		value := skillDamage(attrs, &skill, nil)
+		if value > bestValue {
+			bestValue = value
+			best = &skill
+		}
+	}
+
+	return best, bestValue
+}
+
+func getItemRest(item *swagger.DungeonsandtrollsItem, attrs *swagger.DungeonsandtrollsAttributes) (*swagger.DungeonsandtrollsSkill, float32) {
+	var best *swagger.DungeonsandtrollsSkill
+	bestValue := float32(0)
+
+	for _, skill := range item.Skills {
+		skill := skill
+
+		if !skill.Flags.Passive && skill.CasterEffects != nil && skill.CasterEffects.Attributes != nil && skill.CasterEffects.Attributes.Stamina != nil && skill.CasterEffects.Attributes.Mana != nil {
+			stam := calculateAttributesValue(attrs, skill.CasterEffects.Attributes.Stamina)
+			mana := calculateAttributesValue(attrs, skill.CasterEffects.Attributes.Mana)
+			value := stam * mana * mana
+			value *= value
+			if value > bestValue {
+				bestValue = value
+				best = &skill
+			}
+		}
+	}
+
+	return best, bestValue
+}
+
+func getItemPatch(item *swagger.DungeonsandtrollsItem, attrs *swagger.DungeonsandtrollsAttributes) (*swagger.DungeonsandtrollsSkill, float32) {
+	var best *swagger.DungeonsandtrollsSkill
+	bestValue := float32(0)
+
+	for _, skill := range item.Skills {
+		skill := skill
+
+		if skill.TargetEffects != nil && skill.TargetEffects.Attributes != nil && skill.TargetEffects.Attributes.Life != nil {
+			value := calculateAttributesValue(attrs, skill.TargetEffects.Attributes.Life)
+			if value > bestValue {
+				bestValue = value
+				best = &skill
+			}
+		}
+	}
+
+	return best, bestValue
+}
@@ -0,0 +1,78 @@
+package bot
+
+import (
+	"testing"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+)
+
+func TestGetItemDamage(t *testing.T) {
+	fire := swagger.FIRE_DungeonsandtrollsDamageType
+	target := swagger.CHARACTER_SkillTarget
+	item := swagger.DungeonsandtrollsItem{Skills: []swagger.DungeonsandtrollsSkill{{
+		Id:           "fireball",
+		Target:       &target,
+		DamageType:   &fire,
+		DamageAmount: &swagger.DungeonsandtrollsAttributes{Intelligence: 2, Constant: 5},
+	}}}
+
+	tests := []struct {
+		name  string
+		attrs swagger.DungeonsandtrollsAttributes
+		want  float32
+	}{
+		{"constant only", swagger.DungeonsandtrollsAttributes{}, 5},
+		{"scaled and constant", swagger.DungeonsandtrollsAttributes{Intelligence: 3}, 11},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			skill, got := getItemDamage(&item, &tc.attrs, []swagger.DungeonsandtrollsDamageType{fire})
+			if skill == nil || got != tc.want {
+				t.Errorf("damage %v of %v, want %v", got, skill, tc.want)
+			}
+		})
+	}
+}
+
+func TestShopCache(t *testing.T) {
+	fire := swagger.FIRE_DungeonsandtrollsDamageType
+	target := swagger.CHARACTER_SkillTarget
+	staff := testItem("staff", swagger.MAIN_HAND_DungeonsandtrollsItemType, 100, swagger.DungeonsandtrollsAttributes{}, swagger.DungeonsandtrollsAttributes{})
+	staff.Skills = []swagger.DungeonsandtrollsSkill{{
+		Id:           "fireball",
+		Target:       &target,
+		DamageType:   &fire,
+		DamageAmount: &swagger.DungeonsandtrollsAttributes{Constant: 5},
+		Flags:        &swagger.DungeonsandtrollsSkillGenericFlags{},
+	}}
+	state := &swagger.DungeonsandtrollsGameState{
+		Character: &swagger.DungeonsandtrollsCharacter{
+			Attributes:    &swagger.DungeonsandtrollsAttributes{Life: 50},
+			MaxAttributes: &swagger.DungeonsandtrollsAttributes{Life: 100},
+			Money:         50,
+		},
+		ShopItems: []swagger.DungeonsandtrollsItem{staff},
+	}
+	types := []swagger.DungeonsandtrollsDamageType{fire}
+	d := Default{ShopCache: NewShopCache()}
+
+	if got := d.shop(state, types); len(got) != 0 {
+		t.Fatalf("bought %v without money", got)
+	}
+	state.Character.Money = 100
+	if got := d.shop(state, types); len(got) != 1 {
+		t.Fatalf("bought %v after getting money, want the staff", got)
+	}
+	// A change the search doesn't depend on reuses the result.
+	state.Tick++
+	state.ShopItems[0].Name = "renamed"
+	if got := d.shop(state, types); len(got) != 1 || got[0].Name != "staff" {
+		t.Errorf("bought %v, want the cached staff", got)
+	}
+	// So does regenerating life.
+	state.Tick++
+	state.Character.Attributes.Life = 60
+	if got := d.shop(state, types); len(got) != 1 || got[0].Name != "staff" {
+		t.Errorf("bought %v after regenerating, want the cached staff", got)
+	}
+}
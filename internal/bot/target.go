@@ -0,0 +1,146 @@
+package bot
+
+import (
+	"log"
+	"math"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	// stairsGuardDistance is how close to the stairs a monster has to be to block our way down.
+	stairsGuardDistance = 3
+	stairsGuardBonus    = 1.5
+	// lineOfSightPenalty is the number of turns we expect to lose getting a monster out of cover into view.
+	lineOfSightPenalty = 2
+)
+
+type target struct {
+	object  *swagger.DungeonsandtrollsMapObjects
+	monster swagger.DungeonsandtrollsMonster
+	score   float32
+}
+
+// findTarget picks the monster that is worth killing first.
+// Monsters that deal a lot of damage, guard the stairs and die quickly score higher,
+// the turns needed to get in range and kill them lower the score.
+func findTarget(state *swagger.DungeonsandtrollsGameState, skill *swagger.DungeonsandtrollsSkill, stairs *swagger.DungeonsandtrollsPosition) *target {
+	rang := 0
+	if skill != nil {
+		rang = skillReach(state.Character.Attributes, skill)
+	}
+
+	var best *target
+	for _, level := range state.Map_.Levels {
+		if level.Level != state.CurrentLevel {
+			continue
+		}
+
+		for i := range level.Objects {
+			object := &level.Objects[i]
+			if len(object.Monsters) == 0 {
+				continue
+			}
+
+			dist := mapDistance(*object.Position, *state)
+			if dist == math.MaxInt {
+				continue
+			}
+			turns := float32(max(0, dist-rang))
+			if !lineOfSight(*object.Position, *state) {
+				turns += lineOfSightPenalty
+			}
+			bonus := float32(1)
+			if stairs != nil && distance(*object.Position, *stairs) <= stairsGuardDistance {
+				bonus = stairsGuardBonus
+			}
+
+			for _, monster := range object.Monsters {
+				if monster.Faction == "neutral" {
+					continue
+				}
+
+				threat := float32(0)
+				for _, attack := range monsterAttacks(monster, state.Character.Attributes) {
+					threat += attack.damage
+				}
+				damage := float32(1)
+				if skill != nil {
+					damage = max(damage, skillDamage(state.Character.Attributes, skill, monster.Attributes))
+				}
+				timeToKill := float32(math.Ceil(float64(monsterLife(monster) / damage)))
+
+				score := (1 + threat) * bonus / (1 + timeToKill + turns)
+				if best == nil || score > best.score {
+					best = &target{
+						object:  object,
+						monster: monster,
+						score:   score,
+					}
+				}
+			}
+		}
+	}
+
+	if best != nil {
+		log.Printf("Targeting %s on position %+v with score %.2f\n", best.monster.Name, best.object.Position, best.score)
+	}
+	return best
+}
+
+// monsterLife returns the remaining life of the monster, estimated from its life percentage if its attributes are unknown.
+func monsterLife(monster swagger.DungeonsandtrollsMonster) float32 {
+	if monster.Attributes != nil && monster.Attributes.Life > 0 {
+		return monster.Attributes.Life
+	}
+	if monster.MaxAttributes != nil && monster.MaxAttributes.Life > 0 {
+		return monster.MaxAttributes.Life * monster.LifePercentage / 100
+	}
+	return monster.LifePercentage
+}
+
+// chooseAttackSkill picks the usable attack skill with the best damage and range.
+// Against a known target the damage accounts for its resistances and any damage type is considered,
+// the range is capped at the distance to the target.
+// Without a target only skills of the given damage types are considered.
+func chooseAttackSkill(state *swagger.DungeonsandtrollsGameState, target *target, types []swagger.DungeonsandtrollsDamageType) *swagger.DungeonsandtrollsSkill {
+	var attackSkill *swagger.DungeonsandtrollsSkill
+
+	maxDamage := float32(0)
+	for _, equip := range state.Character.Equip {
+		for _, equipSkill := range equip.Skills {
+			equipSkill := equipSkill
+
+			if !isAttackSkill(&equipSkill) {
+				continue
+			}
+			if target == nil && !slices.Contains(types, *equipSkill.DamageType) {
+				continue
+			}
+
+			if haveRequiredAttirbutes(state.Character.Attributes, equipSkill.Cost) {
+				rang := float32(skillReach(state.Character.Attributes, &equipSkill))
+				var resists *swagger.DungeonsandtrollsAttributes
+				if target != nil {
+					rang = min(rang, float32(distance(*state.CurrentPosition, *target.object.Position)))
+					resists = target.monster.Attributes
+				}
+				damage := skillDamage(state.Character.Attributes, &equipSkill, resists) * rang
+				if target != nil && isAreaSkill(&equipSkill) {
+					area := bestArea(state, &equipSkill, target)
+					if area == nil {
+						continue
+					}
+					damage = area.damage * rang
+				}
+				if damage > maxDamage {
+					maxDamage = damage
+					attackSkill = &equipSkill
+				}
+			}
+		}
+	}
+
+	return attackSkill
+}
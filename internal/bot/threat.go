@@ -0,0 +1,113 @@
+package bot
+
+import (
+	"math"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+	"github.com/liennie/gdt/internal/grid"
+)
+
+// DefaultRiskTolerance is the damage we are willing to take to save a single step.
+const DefaultRiskTolerance = 1
+
+// monsterReach is how many tiles a monster can close in on us before attacking.
+const monsterReach = 1
+
+// threatMap estimates the damage per tick the character would take on the tiles of the current level.
+type threatMap struct {
+	damage map[swagger.DungeonsandtrollsPosition]float32
+}
+
+// newThreatMap builds the threat map of the current level, ignoring monsters on the except object.
+func newThreatMap(state *swagger.DungeonsandtrollsGameState, except *swagger.DungeonsandtrollsMapObjects) *threatMap {
+	t := &threatMap{
+		damage: map[swagger.DungeonsandtrollsPosition]float32{},
+	}
+
+	for _, level := range state.Map_.Levels {
+		if level.Level != state.CurrentLevel {
+			continue
+		}
+
+		for _, object := range level.Objects {
+			for _, effect := range object.Effects {
+				if effect.DamageAmount > 0 {
+					t.damage[*object.Position] += effect.DamageAmount
+				}
+			}
+
+			if except != nil && *object.Position == *except.Position {
+				continue
+			}
+			for _, monster := range object.Monsters {
+				if monster.Faction == "neutral" {
+					continue
+				}
+				for _, attack := range monsterAttacks(monster, state.Character.Attributes) {
+					t.add(*object.Position, attack.reach+monsterReach, attack.damage)
+				}
+			}
+		}
+	}
+
+	return t
+}
+
+type monsterAttack struct {
+	reach  int
+	damage float32
+}
+
+// monsterAttacks estimates the reach and damage of each damaging skill the monster has equipped
+// against a defender with the given attributes.
+func monsterAttacks(monster swagger.DungeonsandtrollsMonster, defender *swagger.DungeonsandtrollsAttributes) []monsterAttack {
+	if monster.Attributes == nil {
+		return nil
+	}
+
+	var res []monsterAttack
+	for _, item := range monster.EquippedItems {
+		for _, skill := range item.Skills {
+			if skill.DamageAmount == nil || skill.Range_ == nil {
+				continue
+			}
+			damage := skillDamage(monster.Attributes, &skill, defender)
+			if damage <= 0 {
+				continue
+			}
+			res = append(res, monsterAttack{
+				reach:  int(math.Trunc(float64(calculateAttributesValue(monster.Attributes, skill.Range_)))),
+				damage: damage,
+			})
+		}
+	}
+	return res
+}
+
+// add spreads damage over every tile within reach of pos.
+func (t *threatMap) add(pos swagger.DungeonsandtrollsPosition, reach int, damage float32) {
+	for dx := -reach; dx <= reach; dx++ {
+		rest := reach - abs(dx)
+		for dy := -rest; dy <= rest; dy++ {
+			tile := swagger.DungeonsandtrollsPosition{
+				PositionX: pos.PositionX + int32(dx),
+				PositionY: pos.PositionY + int32(dy),
+			}
+			t.damage[tile] += damage
+		}
+	}
+}
+
+func (t *threatMap) Damage(pos swagger.DungeonsandtrollsPosition) float32 {
+	return t.damage[pos]
+}
+
+// cost converts expected damage into steps: riskTolerance points of damage cost as much as a single step.
+func (t *threatMap) cost(riskTolerance float64) grid.Cost {
+	if riskTolerance <= 0 {
+		riskTolerance = DefaultRiskTolerance
+	}
+	return func(pos swagger.DungeonsandtrollsPosition) float64 {
+		return float64(t.damage[pos]) / riskTolerance
+	}
+}
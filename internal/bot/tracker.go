@@ -0,0 +1,164 @@
+package bot
+
+import (
+	"log"
+	"math"
+	"strings"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+)
+
+// SkillTracker remembers skills cast across ticks to learn their cooldowns and how fast our resources regenerate,
+// so we don't issue skills the server would reject.
+type SkillTracker struct {
+	history
+
+	// last is the tick each skill was last cast at.
+	last map[string]int32
+	// cooldowns are the learned numbers of ticks between two casts of a skill. Rejected casts raise them
+	// and successful casts lower them.
+	cooldowns map[string]int32
+	// regen is the observed life, stamina and mana regeneration per tick.
+	regen swagger.DungeonsandtrollsAttributes
+}
+
+func NewSkillTracker() *SkillTracker {
+	return &SkillTracker{
+		last:      map[string]int32{},
+		cooldowns: map[string]int32{},
+	}
+}
+
+// Observe updates the tracker with the state of a new tick.
+func (t *SkillTracker) Observe(state swagger.DungeonsandtrollsGameState) {
+	if state.Character == nil || state.Character.Attributes == nil {
+		return
+	}
+	next, restarted := t.advance(&state)
+	if !next {
+		return
+	}
+	if restarted {
+		// The cooldowns are the same in the new game, the casts are not.
+		t.last = map[string]int32{}
+	}
+
+	if t.previous != nil && t.command != nil && t.command.Skill != nil {
+		id := t.command.Skill.SkillId
+		last, cast := t.last[id]
+		skill := equippedSkill(t.previous, id)
+		if rejected(state) {
+			// Skills we couldn't pay for tell nothing about the cooldown. Neither does a rejection
+			// of a cast we didn't expect to be off cooldown, unless the server blames the cooldown,
+			// as the error may be about the target, the rest of the command or anything else.
+			affordable := skill != nil && (skill.Cost == nil || haveRequiredAttirbutes(t.previous.Character.Attributes, skill.Cost))
+			if cast && affordable && (t.previous.Tick == last+max(t.cooldowns[id], 1) || rejectedOnCooldown(state)) {
+				cooldown := t.previous.Tick - last + 1
+				if cooldown > t.cooldowns[id] {
+					log.Printf("Skill %s was rejected, learned cooldown %d\n", id, cooldown)
+					t.cooldowns[id] = cooldown
+				}
+			}
+		} else {
+			// A successful cast shows the cooldown is at most the ticks since the last one.
+			if cast && t.previous.Tick-last < t.cooldowns[id] {
+				log.Printf("Skill %s was cast again after %d ticks, lowering its cooldown\n", id, t.previous.Tick-last)
+				t.cooldowns[id] = t.previous.Tick - last
+			}
+			t.last[id] = t.previous.Tick
+		}
+	}
+
+	casted := t.command != nil && t.command.Skill != nil
+	if t.previous != nil && !casted && state.Tick == t.previous.Tick+1 {
+		before := t.previous.Character.Attributes
+		after := state.Character.Attributes
+		maxAttrs := state.Character.MaxAttributes
+		learn := func(regen *float32, before, after, max float32) {
+			if before < max && after >= before {
+				*regen = (*regen + after - before) / 2
+			}
+		}
+		if maxAttrs != nil {
+			learn(&t.regen.Life, before.Life, after.Life, maxAttrs.Life)
+			learn(&t.regen.Stamina, before.Stamina, after.Stamina, maxAttrs.Stamina)
+			learn(&t.regen.Mana, before.Mana, after.Mana, maxAttrs.Mana)
+		}
+	}
+
+	t.record(&state)
+}
+
+func equippedSkill(state *swagger.DungeonsandtrollsGameState, id string) *swagger.DungeonsandtrollsSkill {
+	for _, item := range state.Character.Equip {
+		for i := range item.Skills {
+			if item.Skills[i].Id == id {
+				return &item.Skills[i]
+			}
+		}
+	}
+	return nil
+}
+
+// rejected reports whether the state has an error event of our character.
+func rejected(state swagger.DungeonsandtrollsGameState) bool {
+	for _, event := range state.Events {
+		if event.Type_ != nil && *event.Type_ == swagger.ERROR__DungeonsandtrollsEventType && event.PlayerId == state.Character.Id {
+			return true
+		}
+	}
+	return false
+}
+
+// rejectedOnCooldown reports whether the state has an error event of our character about a cooldown.
+func rejectedOnCooldown(state swagger.DungeonsandtrollsGameState) bool {
+	for _, event := range state.Events {
+		if event.Type_ != nil && *event.Type_ == swagger.ERROR__DungeonsandtrollsEventType && event.PlayerId == state.Character.Id &&
+			strings.Contains(strings.ToLower(event.Message), "cooldown") {
+			return true
+		}
+	}
+	return false
+}
+
+// offCooldownAt returns the first tick, starting at the tick of the state, the skill is off cooldown.
+func (t *SkillTracker) offCooldownAt(state *swagger.DungeonsandtrollsGameState, skill *swagger.DungeonsandtrollsSkill) int32 {
+	if last, ok := t.last[skill.Id]; ok {
+		return max(state.Tick, last+t.cooldown(skill))
+	}
+	return state.Tick
+}
+
+// cooldown returns the learned number of ticks between two casts of the skill, at least one.
+func (t *SkillTracker) cooldown(skill *swagger.DungeonsandtrollsSkill) int32 {
+	return max(t.cooldowns[skill.Id], 1)
+}
+
+// CastableAt returns the first tick, starting at the tick of the state, the skill is off cooldown
+// and we are expected to have regenerated enough resources to pay for it.
+// It returns math.MaxInt32 if we never regenerate enough.
+func (t *SkillTracker) CastableAt(state *swagger.DungeonsandtrollsGameState, skill *swagger.DungeonsandtrollsSkill) int32 {
+	tick := t.offCooldownAt(state, skill)
+	if skill.Cost == nil {
+		return tick
+	}
+	attrs := state.Character.Attributes
+	wait := func(have, need, regen float32) int32 {
+		if have >= need {
+			return 0
+		}
+		if regen <= 0 {
+			return math.MaxInt32
+		}
+		return int32(math.Ceil(float64((need - have) / regen)))
+	}
+	waits := max(
+		wait(attrs.Life, skill.Cost.Life, t.regen.Life),
+		wait(attrs.Stamina, skill.Cost.Stamina, t.regen.Stamina),
+		wait(attrs.Mana, skill.Cost.Mana, t.regen.Mana),
+	)
+	if waits == math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return max(tick, state.Tick+waits)
+}
@@ -0,0 +1,148 @@
+package bot
+
+import (
+	"testing"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+)
+
+func TestSkillTracker(t *testing.T) {
+	skill := swagger.DungeonsandtrollsSkill{
+		Id:   "clap",
+		Cost: &swagger.DungeonsandtrollsAttributes{Mana: 5},
+	}
+	// state has the command of the previous tick rejected with the message, unless it is empty.
+	state := func(tick int32, mana float32, rejection string) swagger.DungeonsandtrollsGameState {
+		s := testState(tick, 0, 1)
+		s.Character.Attributes.Mana = mana
+		s.Character.MaxAttributes.Mana = 50
+		testEquip(&s, skill)
+		if rejection != "" {
+			testReject(&s, rejection)
+		}
+		return s
+	}
+	cast := &swagger.DungeonsandtrollsCommandsBatch{Skill: &swagger.DungeonsandtrollsSkillUse{SkillId: skill.Id}}
+
+	tests := []struct {
+		name  string
+		steps []testStep
+		// mana is what we have when asking in the tick of the last step.
+		mana         float32
+		wantCooldown int32
+		wantCastable int32
+	}{
+		{
+			name:         "castable before casting",
+			steps:        []testStep{{state(1, 20, ""), nil}},
+			mana:         20,
+			wantCastable: 1,
+		},
+		{
+			name:         "without a known cooldown the skill is castable the next tick",
+			steps:        []testStep{{state(1, 20, ""), cast}, {state(2, 15, ""), nil}},
+			mana:         15,
+			wantCastable: 2,
+		},
+		{
+			name: "rejected when expected off cooldown",
+			steps: []testStep{
+				{state(1, 20, ""), cast},
+				{state(2, 15, ""), cast},
+				{state(3, 15, "error"), nil},
+			},
+			mana:         15,
+			wantCooldown: 2,
+			wantCastable: 3,
+		},
+		{
+			name: "rejected while waiting for a learned cooldown",
+			steps: []testStep{
+				{state(1, 20, ""), cast},
+				{state(2, 15, ""), cast},
+				{state(3, 15, "error"), cast},
+				{state(4, 15, "error"), nil},
+			},
+			mana:         15,
+			wantCooldown: 3,
+			wantCastable: 4,
+		},
+		{
+			name: "rejected long after the last cast",
+			steps: []testStep{
+				{state(1, 20, ""), cast},
+				{state(10, 15, ""), cast},
+				{state(11, 15, "target out of range"), nil},
+			},
+			mana:         15,
+			wantCastable: 11,
+		},
+		{
+			name: "rejected on cooldown long after the last cast",
+			steps: []testStep{
+				{state(1, 20, ""), cast},
+				{state(5, 15, ""), cast},
+				{state(6, 15, "Skill is on cooldown"), nil},
+			},
+			mana:         15,
+			wantCooldown: 5,
+			wantCastable: 6,
+		},
+		{
+			name: "rejected without mana to pay",
+			steps: []testStep{
+				{state(1, 20, ""), cast},
+				{state(2, 3, ""), cast},
+				{state(3, 3, "error"), nil},
+			},
+			mana:         15,
+			wantCastable: 3,
+		},
+		{
+			name: "cast sooner than the learned cooldown",
+			steps: []testStep{
+				{state(1, 20, ""), cast},
+				{state(5, 15, ""), cast},
+				{state(6, 15, "Skill is on cooldown"), cast},
+				{state(8, 10, ""), cast},
+				{state(9, 5, ""), nil},
+			},
+			mana:         5,
+			wantCooldown: 2,
+			wantCastable: 10,
+		},
+		{
+			name: "restarted game",
+			steps: []testStep{
+				{state(1000, 20, ""), cast},
+				{state(1001, 15, ""), cast},
+				{state(1002, 15, "Skill is on cooldown"), nil},
+				{state(5, 15, ""), nil},
+			},
+			mana:         15,
+			wantCooldown: 2,
+			wantCastable: 5,
+		},
+		{
+			// Mana regenerated by 2 without casting, averaged with the initial estimate of no regeneration.
+			name:         "missing mana",
+			steps:        []testStep{{state(1, 15, ""), nil}, {state(2, 17, ""), nil}},
+			mana:         1,
+			wantCastable: 6,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := NewSkillTracker()
+			testObserve(tracker, tt.steps)
+
+			if got := tracker.cooldowns[skill.Id]; got != tt.wantCooldown {
+				t.Errorf("cooldown %d, want %d", got, tt.wantCooldown)
+			}
+			s := state(tt.steps[len(tt.steps)-1].state.Tick, tt.mana, "")
+			if got := tracker.CastableAt(&s, &skill); got != tt.wantCastable {
+				t.Errorf("castable at %d, want %d", got, tt.wantCastable)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package bot
+
+import (
+	"log"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+	"github.com/liennie/gdt/internal/grid"
+)
+
+const (
+	// skillFailureLimit is how many casts of a skill in a row may do nothing before it gets blacklisted.
+	skillFailureLimit = 2
+	// skillBlacklistTicks is how long a blacklisted skill is not used.
+	skillBlacklistTicks = 10
+	// blockedTileTicks is how long a tile we failed to move to is avoided.
+	blockedTileTicks = 10
+	// blockedTileCost is how many steps we are willing to walk around a blocked tile.
+	blockedTileCost = 10
+)
+
+// Verifier compares the command sent in a tick with the state of the next tick and remembers what failed.
+// Skills that repeatedly do nothing are blacklisted for a while and tiles we failed to move to are avoided.
+type Verifier struct {
+	history
+
+	failures  map[string]int
+	blacklist map[string]int32
+	blocked   map[swagger.DungeonsandtrollsPosition]int32
+}
+
+func NewVerifier() *Verifier {
+	v := &Verifier{}
+	v.forget()
+	return v
+}
+
+// forget clears what failed, as in a new game.
+func (v *Verifier) forget() {
+	v.failures = map[string]int{}
+	v.blacklist = map[string]int32{}
+	v.blocked = map[swagger.DungeonsandtrollsPosition]int32{}
+}
+
+// Observe checks the outcome of the last issued command against the state of a new tick.
+func (v *Verifier) Observe(state swagger.DungeonsandtrollsGameState) {
+	if state.Character == nil || state.CurrentPosition == nil {
+		return
+	}
+	next, restarted := v.advance(&state)
+	if !next {
+		return
+	}
+	if restarted {
+		v.forget()
+	}
+
+	if v.previous != nil && v.command != nil {
+		if use := v.command.Skill; use != nil {
+			if skillWorked(v.previous, &state, use) {
+				v.failures[use.SkillId] = 0
+			} else {
+				v.failures[use.SkillId]++
+				log.Printf("Skill %s did nothing %d times in a row\n", use.SkillId, v.failures[use.SkillId])
+				if v.failures[use.SkillId] >= skillFailureLimit {
+					v.blacklist[use.SkillId] = state.Tick + skillBlacklistTicks
+					v.failures[use.SkillId] = 0
+				}
+			}
+		}
+
+		// A rejected batch or a stun stops the move before it gets to any tile.
+		if move := v.command.Move; move != nil && !rejected(state) && !stunned(v.previous.Character) && !stunned(state.Character) && state.CurrentLevel == v.previous.CurrentLevel &&
+			*move != *v.previous.CurrentPosition && *state.CurrentPosition == *v.previous.CurrentPosition {
+			step := nextStep(v.previous, *move)
+			log.Printf("Failed to move towards %+v, avoiding %+v\n", *move, step)
+			v.blocked[step] = state.Tick + blockedTileTicks
+		}
+	}
+
+	v.record(&state)
+}
+
+func (v *Verifier) blacklisted(skill *swagger.DungeonsandtrollsSkill, tick int32) bool {
+	return tick < v.blacklist[skill.Id]
+}
+
+// cost makes the tiles we failed to move to expensive.
+func (v *Verifier) cost(tick int32) grid.Cost {
+	return func(pos swagger.DungeonsandtrollsPosition) float64 {
+		if tick < v.blocked[pos] {
+			return blockedTileCost
+		}
+		return 0
+	}
+}
+
+func stunned(character *swagger.DungeonsandtrollsCharacter) bool {
+	return character.Stun != nil && character.Stun.IsStunned
+}
+
+// nextStep returns the first tile on the shortest path from the position of the state to goal,
+// the tile the server moves us to first. Without a known path it is the goal itself.
+func nextStep(state *swagger.DungeonsandtrollsGameState, goal swagger.DungeonsandtrollsPosition) swagger.DungeonsandtrollsPosition {
+	if state.Map_ == nil {
+		return goal
+	}
+	if g := currentGrid(state); g != nil {
+		if path, ok := g.Path(*state.CurrentPosition, goal, nil); ok && len(path.Steps) > 0 {
+			return path.Steps[0]
+		}
+	}
+	return goal
+}
+
+// skillWorked reports whether the skill use shows in the next state: it wasn't rejected,
+// its cost was paid and a damaged monster target lost life.
+func skillWorked(before, after *swagger.DungeonsandtrollsGameState, use *swagger.DungeonsandtrollsSkillUse) bool {
+	skill := equippedSkill(before, use.SkillId)
+	if skill == nil || rejected(*after) {
+		return false
+	}
+
+	// Caster effects may restore what the skill costs.
+	if skill.Cost != nil && skill.CasterEffects == nil {
+		spent := func(before, after, cost float32) bool {
+			return cost <= 0 || after < before
+		}
+		b, a := before.Character.Attributes, after.Character.Attributes
+		if !spent(b.Mana, a.Mana, skill.Cost.Mana) || !spent(b.Stamina, a.Stamina, skill.Cost.Stamina) {
+			return false
+		}
+	}
+
+	if use.TargetId != "" && use.TargetId != before.Character.Id && skill.DamageAmount != nil {
+		life, ok := monsterLifeByID(before, use.TargetId)
+		if !ok {
+			return true
+		}
+		// A monster that died or walked out of sight tells nothing.
+		if lifeAfter, ok := monsterLifeByID(after, use.TargetId); ok && lifeAfter >= life {
+			return false
+		}
+	}
+	return true
+}
+
+func monsterLifeByID(state *swagger.DungeonsandtrollsGameState, id string) (float32, bool) {
+	for _, level := range state.Map_.Levels {
+		if level.Level != state.CurrentLevel {
+			continue
+		}
+		for _, object := range level.Objects {
+			for _, monster := range object.Monsters {
+				if monster.Id == id && monster.Attributes != nil {
+					return monster.Attributes.Life, true
+				}
+			}
+		}
+	}
+	return 0, false
+}
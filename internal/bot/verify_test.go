@@ -0,0 +1,157 @@
+package bot
+
+import (
+	"testing"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+	"golang.org/x/exp/slices"
+)
+
+func TestVerifier(t *testing.T) {
+	skill := swagger.DungeonsandtrollsSkill{
+		Id:           "slash",
+		DamageAmount: &swagger.DungeonsandtrollsAttributes{Constant: 5},
+	}
+	// state is on a corridor of 5 tiles with the monster of the given life out of the way.
+	state := func(tick, x int32, life float32) swagger.DungeonsandtrollsGameState {
+		s := testState(tick, x, 5)
+		testEquip(&s, skill)
+		s.Map_.Levels[0].Objects = []swagger.DungeonsandtrollsMapObjects{{
+			Monsters: []swagger.DungeonsandtrollsMonster{{Id: "goblin", Attributes: &swagger.DungeonsandtrollsAttributes{Life: life}}},
+		}}
+		return s
+	}
+	rejected := func(s swagger.DungeonsandtrollsGameState) swagger.DungeonsandtrollsGameState {
+		testReject(&s, "error")
+		return s
+	}
+	stunned := func(s swagger.DungeonsandtrollsGameState) swagger.DungeonsandtrollsGameState {
+		s.Character.Stun = &swagger.DungeonsandtrollsStun{IsStunned: true}
+		return s
+	}
+	cast := &swagger.DungeonsandtrollsCommandsBatch{Skill: &swagger.DungeonsandtrollsSkillUse{SkillId: skill.Id, TargetId: "goblin"}}
+	move := &swagger.DungeonsandtrollsCommandsBatch{Move: &swagger.DungeonsandtrollsPosition{PositionX: 4}}
+
+	tests := []struct {
+		name  string
+		steps []testStep
+		// after is how many ticks after the last step to check.
+		after           int32
+		wantBlacklisted bool
+		// wantBlocked are the x coordinates of the tiles to avoid.
+		wantBlocked []int32
+	}{
+		{
+			name:  "skill dealt damage",
+			steps: []testStep{{state(1, 0, 10), cast}, {state(2, 0, 5), nil}},
+		},
+		{
+			name:  "skill did nothing once",
+			steps: []testStep{{state(1, 0, 10), cast}, {state(2, 0, 10), nil}},
+		},
+		{
+			name: "skill did nothing twice",
+			steps: []testStep{
+				{state(1, 0, 10), cast},
+				{state(2, 0, 10), cast},
+				{state(3, 0, 10), nil},
+			},
+			wantBlacklisted: true,
+		},
+		{
+			name: "skill rejected twice",
+			steps: []testStep{
+				{state(1, 0, 10), cast},
+				{rejected(state(2, 0, 5)), cast},
+				{rejected(state(3, 0, 0)), nil},
+			},
+			wantBlacklisted: true,
+		},
+		{
+			name: "blacklist expired",
+			steps: []testStep{
+				{state(1, 0, 10), cast},
+				{state(2, 0, 10), cast},
+				{state(3, 0, 10), nil},
+			},
+			after: skillBlacklistTicks,
+		},
+		{
+			name:  "moved",
+			steps: []testStep{{state(1, 0, 0), move}, {state(2, 1, 0), nil}},
+		},
+		{
+			name:        "failed to move blocks the next step",
+			steps:       []testStep{{state(1, 0, 0), move}, {state(2, 0, 0), nil}},
+			wantBlocked: []int32{1},
+		},
+		{
+			name:  "blocked tile expired",
+			steps: []testStep{{state(1, 0, 0), move}, {state(2, 0, 0), nil}},
+			after: blockedTileTicks,
+		},
+		{
+			name:  "move rejected with the batch",
+			steps: []testStep{{state(1, 0, 0), move}, {rejected(state(2, 0, 0)), nil}},
+		},
+		{
+			name:  "stunned",
+			steps: []testStep{{stunned(state(1, 0, 0)), move}, {state(2, 0, 0), nil}},
+		},
+		{
+			name: "restarted game",
+			steps: []testStep{
+				{state(1000, 0, 10), cast},
+				{state(1001, 0, 10), cast},
+				{state(1002, 0, 10), move},
+				{state(1003, 0, 10), nil},
+				{state(5, 0, 10), nil},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := NewVerifier()
+			testObserve(verifier, tt.steps)
+
+			tick := tt.steps[len(tt.steps)-1].state.Tick + tt.after
+			if got := verifier.blacklisted(&skill, tick); got != tt.wantBlacklisted {
+				t.Errorf("blacklisted %v, want %v", got, tt.wantBlacklisted)
+			}
+			var blocked []int32
+			cost := verifier.cost(tick)
+			for x := int32(0); x < 5; x++ {
+				if c := cost(swagger.DungeonsandtrollsPosition{PositionX: x}); c != 0 {
+					if c != blockedTileCost {
+						t.Errorf("blocked tile cost %v, want %v", c, blockedTileCost)
+					}
+					blocked = append(blocked, x)
+				}
+			}
+			if !slices.Equal(blocked, tt.wantBlocked) {
+				t.Errorf("blocked %v, want %v", blocked, tt.wantBlocked)
+			}
+		})
+	}
+}
+
+func TestVerifierDetour(t *testing.T) {
+	// An open room of 5x3 tiles, we fail to step along the middle row towards its other end.
+	state := func(tick int32) swagger.DungeonsandtrollsGameState {
+		s := testState(tick, 0, 5)
+		s.CurrentPosition.PositionY = 1
+		s.Map_.Levels[0].Height = 3
+		return s
+	}
+	goal := swagger.DungeonsandtrollsPosition{PositionX: 4, PositionY: 1}
+
+	play := Default{Verifier: NewVerifier()}
+	play.Verifier.Observe(state(1))
+	play.Verifier.Issued(&swagger.DungeonsandtrollsCommandsBatch{Move: &goal})
+	s := state(2)
+	play.Verifier.Observe(s)
+
+	if got := moveTowards(&s, goal, play.moveCost(&s, nil)); got == nil || got.PositionY == 1 {
+		t.Errorf("move %+v, want a waypoint off the blocked row", got)
+	}
+}
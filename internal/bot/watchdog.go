@@ -0,0 +1,282 @@
+package bot
+
+import (
+	"log"
+	"math"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+	"github.com/liennie/gdt/internal/grid"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	// stuckTicks is how many ticks without progress make us stuck.
+	stuckTicks = 10
+	// stuckWindow is how many recent positions count as visited, so walking back and forth is no progress.
+	stuckWindow = 6
+	// errorLimit is how many commands in a row the server may reject before we are stuck.
+	errorLimit = 5
+	// recoveryTicks is the longest a single recovery behavior runs.
+	recoveryTicks = 15
+)
+
+type recovery int
+
+const (
+	recoverNone recovery = iota
+	recoverExplore
+	recoverPortal
+	recoverSpawn
+	recoverRespawn
+)
+
+func (r recovery) String() string {
+	switch r {
+	case recoverExplore:
+		return "explore"
+	case recoverPortal:
+		return "another portal"
+	case recoverSpawn:
+		return "spawn"
+	case recoverRespawn:
+		return "respawn"
+	}
+	return "none"
+}
+
+// Watchdog detects that the bot makes no progress, standing at one place, walking back and forth
+// or getting its commands rejected, and picks a recovery behavior.
+// Each time we get stuck again on the same level the next, more drastic, behavior is tried:
+// exploring unseen tiles, going to another portal, returning to the spawn and finally respawning.
+type Watchdog struct {
+	history
+
+	visited []swagger.DungeonsandtrollsPosition
+	idle    int
+	errors  int
+
+	// stuck is set when a recovery is due to start.
+	stuck bool
+	// last is the last recovery tried on the current level.
+	last     recovery
+	active   recovery
+	goal     *swagger.DungeonsandtrollsPosition
+	deadline int32
+	// respawn is set when the last decision was to respawn.
+	respawn bool
+}
+
+func NewWatchdog() *Watchdog {
+	return &Watchdog{}
+}
+
+// Observe checks the progress made by the last issued command against the state of a new tick.
+func (w *Watchdog) Observe(state swagger.DungeonsandtrollsGameState) {
+	if state.Character == nil || state.CurrentPosition == nil {
+		return
+	}
+	next, restarted := w.advance(&state)
+	if !next {
+		return
+	}
+
+	if restarted || w.previous != nil && state.CurrentLevel != w.previous.CurrentLevel {
+		w.forget()
+	} else if w.previous != nil {
+		w.progress(&state)
+	}
+
+	w.visited = append(w.visited, *state.CurrentPosition)
+	if len(w.visited) > stuckWindow {
+		w.visited = w.visited[1:]
+	}
+
+	// Walking away from a fight only gets us hit without fighting back.
+	fight := fighting(&state)
+	if w.active != recoverNone && fight {
+		log.Println("Recovery", w.active, "interrupted by a fight")
+		w.active = recoverNone
+	}
+	if w.active != recoverNone && (state.Tick >= w.deadline || w.goal != nil && *w.goal == *state.CurrentPosition) {
+		log.Println("Recovery", w.active, "finished")
+		w.active = recoverNone
+	}
+	if w.active == recoverNone && !fight && (w.idle >= stuckTicks || w.errors >= errorLimit) {
+		log.Printf("Stuck for %d ticks with %d errors\n", w.idle, w.errors)
+		w.idle = 0
+		w.errors = 0
+		w.stuck = true
+	}
+
+	w.record(&state)
+}
+
+// forget starts over on a new level or in a new game.
+func (w *Watchdog) forget() {
+	w.visited = nil
+	w.idle = 0
+	w.errors = 0
+	w.stuck = false
+	w.last = recoverNone
+	w.active = recoverNone
+}
+
+// progress counts the ticks without progress and the rejected commands.
+func (w *Watchdog) progress(state *swagger.DungeonsandtrollsGameState) {
+	if rejected(*state) {
+		w.errors++
+	} else {
+		w.errors = 0
+	}
+
+	command := w.command
+	switch {
+	case !slices.Contains(w.visited, *state.CurrentPosition):
+		w.idle = 0
+	case command != nil && (command.Skill != nil || command.Buy != nil || command.PickUp != nil || command.AssignSkillPoints != nil) && !rejected(*state):
+		w.idle = 0
+	case command != nil && command.Move != nil && *command.Move == *w.previous.CurrentPosition:
+		// Waiting on purpose.
+	case command != nil && command.Move == nil && fighting(state):
+		// Waiting for a skill, resting or healing in a fight.
+		w.idle = 0
+	default:
+		w.idle++
+	}
+}
+
+// fighting reports whether a hostile monster is in reach of our attack skills or can hit us.
+func fighting(state *swagger.DungeonsandtrollsGameState) bool {
+	if state.Map_ == nil {
+		return false
+	}
+	attrs := state.Character.Attributes
+	for _, level := range state.Map_.Levels {
+		if level.Level != state.CurrentLevel {
+			continue
+		}
+		for _, object := range level.Objects {
+			if object.Position == nil {
+				continue
+			}
+			for _, monster := range object.Monsters {
+				if monster.Faction == "neutral" {
+					continue
+				}
+				dist := distance(*object.Position, *state.CurrentPosition)
+				for _, attack := range monsterAttacks(monster, attrs) {
+					if dist <= attack.reach {
+						return true
+					}
+				}
+				for _, item := range state.Character.Equip {
+					for i := range item.Skills {
+						skill := &item.Skills[i]
+						if isAttackSkill(skill) && dist <= skillReach(attrs, skill) && lineOfSight(*object.Position, *state) {
+							return true
+						}
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// recoveryGoal returns where the recovery behavior goes, or nil if it has nowhere to go.
+func (d Default) recoveryGoal(state *swagger.DungeonsandtrollsGameState, r recovery) *swagger.DungeonsandtrollsPosition {
+	switch r {
+	case recoverExplore:
+		return d.explore(state)
+	case recoverPortal:
+		return findOtherPortal(state)
+	case recoverSpawn:
+		if spawn := findSpawn(state); spawn != nil && *spawn != *state.CurrentPosition {
+			return spawn
+		}
+	}
+	return nil
+}
+
+// findOtherPortal returns the closest reachable portal or stairs other than the ones findStairs picks.
+func findOtherPortal(state *swagger.DungeonsandtrollsGameState) *swagger.DungeonsandtrollsPosition {
+	stairs := findStairs(state)
+
+	var best *swagger.DungeonsandtrollsPosition
+	bestDist := math.MaxInt
+	for _, level := range state.Map_.Levels {
+		if level.Level != state.CurrentLevel {
+			continue
+		}
+		for _, object := range level.Objects {
+			if object.Portal == nil && !object.IsStairs || object.Position == nil {
+				continue
+			}
+			if stairs != nil && *object.Position == *stairs {
+				continue
+			}
+			if dist := mapDistance(*object.Position, *state); dist < bestDist {
+				best, bestDist = object.Position, dist
+			}
+		}
+	}
+	return best
+}
+
+// startRecovery starts the next recovery behavior that has somewhere to go.
+func (d Default) startRecovery(state *swagger.DungeonsandtrollsGameState) {
+	w := d.Watchdog
+	for next := w.last + 1; next <= recoverRespawn; next++ {
+		w.last = next
+		w.goal = d.recoveryGoal(state, next)
+		if w.goal != nil || next == recoverRespawn {
+			log.Println("Recovering:", next)
+			w.active = next
+			w.deadline = state.Tick + recoveryTicks
+			return
+		}
+	}
+}
+
+// recover returns the command of the running recovery behavior. It returns false if there is none.
+func (d Default) recover(state *swagger.DungeonsandtrollsGameState) (*swagger.DungeonsandtrollsCommandsBatch, bool) {
+	w := d.Watchdog
+	if w == nil {
+		return nil, false
+	}
+	w.respawn = false
+	if w.stuck {
+		w.stuck = false
+		d.startRecovery(state)
+	}
+	if w.active == recoverNone {
+		return nil, false
+	}
+
+	if w.active == recoverRespawn {
+		// The respawn may leave us on the same level, where all the recoveries are worth trying again.
+		log.Println("Stuck, respawning ...")
+		w.active = recoverNone
+		w.last = recoverNone
+		w.respawn = true
+		return nil, true
+	}
+
+	// Detours around danger are what makes us walk back and forth, so take the direct way.
+	var cost grid.Cost
+	if d.Verifier != nil {
+		cost = d.Verifier.cost(state.Tick)
+	}
+	return &swagger.DungeonsandtrollsCommandsBatch{
+		Move: moveTowards(state, *w.goal, grid.Sum(cost)),
+		Yell: &swagger.DungeonsandtrollsMessage{
+			Text: "<color=\"orange\">I'm stuck!</color>",
+		},
+	}, true
+}
+
+// Respawning reports whether the last decision was to respawn the character,
+// which the runner does in place of sending a command.
+func (d Default) Respawning() bool {
+	return d.Watchdog != nil && d.Watchdog.respawn
+}
@@ -0,0 +1,172 @@
+package bot
+
+import (
+	"testing"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+)
+
+func TestWatchdog(t *testing.T) {
+	unseen := swagger.DungeonsandtrollsPosition{PositionX: 2}
+	character := swagger.CHARACTER_SkillTarget
+	goblin := swagger.DungeonsandtrollsMonster{
+		Id:         "goblin",
+		Attributes: &swagger.DungeonsandtrollsAttributes{Life: 10},
+		EquippedItems: []swagger.DungeonsandtrollsItem{{Skills: []swagger.DungeonsandtrollsSkill{{
+			Target:       &character,
+			Range_:       &swagger.DungeonsandtrollsAttributes{Constant: 1},
+			DamageAmount: &swagger.DungeonsandtrollsAttributes{Constant: 5},
+		}}}},
+	}
+	// A corridor with the next tile in sight, the one after it out of sight and a goblin next to us if fighting.
+	state := func(tick int32, x int32, fighting, rejected bool) swagger.DungeonsandtrollsGameState {
+		s := testState(tick, x, 3)
+		s.Map_.Levels[0].PlayerMap = []swagger.DungeonsandtrollsPlayerSpecificMap{
+			{Position: &swagger.DungeonsandtrollsPosition{PositionX: 1}, Distance: 1, LineOfSight: true},
+			{Position: &unseen, Distance: 2},
+		}
+		if fighting {
+			s.Map_.Levels[0].Objects = []swagger.DungeonsandtrollsMapObjects{{
+				Position: &swagger.DungeonsandtrollsPosition{PositionX: x, PositionY: 1},
+				Monsters: []swagger.DungeonsandtrollsMonster{goblin},
+			}}
+		}
+		if rejected {
+			testReject(&s, "error")
+		}
+		return s
+	}
+	move := &swagger.DungeonsandtrollsCommandsBatch{Move: &swagger.DungeonsandtrollsPosition{PositionX: 9}}
+	stay := &swagger.DungeonsandtrollsCommandsBatch{Move: &swagger.DungeonsandtrollsPosition{}}
+	wait := &swagger.DungeonsandtrollsCommandsBatch{Yell: &swagger.DungeonsandtrollsMessage{Text: "Waiting."}}
+
+	tests := []struct {
+		name    string
+		ticks   int32
+		x       func(tick int32) int32
+		command *swagger.DungeonsandtrollsCommandsBatch
+		// fightFrom is the first tick with a monster in range, none if zero.
+		fightFrom int32
+		rejected  bool
+		// restartAt is the first tick of a restarted game numbered from zero, the ticks before it from 1000.
+		restartAt int32
+		// wantMove is where the recovery in the last tick goes, nil if not recovering.
+		wantMove      *swagger.DungeonsandtrollsPosition
+		wantRespawned bool
+	}{
+		{
+			name:     "walking back and forth",
+			ticks:    stuckTicks + 2,
+			x:        func(tick int32) int32 { return tick % 2 },
+			command:  move,
+			wantMove: &unseen,
+		},
+		{
+			name:     "standing still",
+			ticks:    stuckTicks + 2,
+			x:        func(int32) int32 { return 0 },
+			command:  move,
+			wantMove: &unseen,
+		},
+		{
+			name:    "waiting on purpose",
+			ticks:   stuckTicks + 2,
+			x:       func(int32) int32 { return 0 },
+			command: stay,
+		},
+		{
+			name:     "waiting out of a fight",
+			ticks:    stuckTicks + 2,
+			x:        func(int32) int32 { return 0 },
+			command:  wait,
+			wantMove: &unseen,
+		},
+		{
+			name:      "waiting in a fight",
+			ticks:     stuckTicks + 2,
+			x:         func(int32) int32 { return 0 },
+			command:   wait,
+			fightFrom: 1,
+		},
+		{
+			name:      "stuck in a fight",
+			ticks:     stuckTicks + 2,
+			x:         func(int32) int32 { return 0 },
+			command:   move,
+			fightFrom: 1,
+		},
+		{
+			name:      "recovery interrupted by a fight",
+			ticks:     stuckTicks + 3,
+			x:         func(int32) int32 { return 0 },
+			command:   move,
+			fightFrom: stuckTicks + 2,
+		},
+		{
+			name:     "rejected commands",
+			ticks:    errorLimit + 2,
+			x:        func(int32) int32 { return 0 },
+			command:  stay,
+			rejected: true,
+			wantMove: &unseen,
+		},
+		{
+			name:      "restarted game",
+			ticks:     3 + stuckTicks + 2,
+			x:         func(int32) int32 { return 0 },
+			command:   move,
+			restartAt: 3,
+			wantMove:  &unseen,
+		},
+		{
+			// Without other portals and a spawn the next recovery after exploring is respawning.
+			name:          "respawning as the last resort",
+			ticks:         stuckTicks + recoveryTicks + 1,
+			x:             func(int32) int32 { return 0 },
+			command:       move,
+			wantRespawned: true,
+		},
+		{
+			name:          "recovering again after a respawn on the same level",
+			ticks:         2*stuckTicks + recoveryTicks + 1,
+			x:             func(int32) int32 { return 0 },
+			command:       move,
+			wantMove:      &unseen,
+			wantRespawned: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			respawned := false
+			watchdog := NewWatchdog()
+			play := Default{Watchdog: watchdog}
+
+			var command *swagger.DungeonsandtrollsCommandsBatch
+			var recovering bool
+			for i := int32(0); i < tt.ticks; i++ {
+				tick := i
+				if tt.restartAt > 0 {
+					tick = i - tt.restartAt
+					if i < tt.restartAt {
+						tick = 1000 + i
+					}
+				}
+				s := state(tick, tt.x(i), tt.fightFrom > 0 && i >= tt.fightFrom, tt.rejected && i > 0)
+				watchdog.Observe(s)
+				command, recovering = play.recover(&s)
+				respawned = respawned || play.Respawning()
+				watchdog.Issued(tt.command)
+			}
+
+			if tt.wantMove == nil && recovering && !play.Respawning() {
+				t.Errorf("recovering with %+v", command)
+			}
+			if tt.wantMove != nil && (!recovering || command == nil || command.Move == nil || *command.Move != *tt.wantMove) {
+				t.Errorf("recovery command %+v, want move to %+v", command, *tt.wantMove)
+			}
+			if respawned != tt.wantRespawned {
+				t.Errorf("respawned %v, want %v", respawned, tt.wantRespawned)
+			}
+		})
+	}
+}
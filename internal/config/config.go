@@ -0,0 +1,105 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	EnvAPIKey  = "DNT_API_KEY"
+	EnvBaseURL = "DNT_BASE_URL"
+	EnvConfig  = "DNT_CONFIG"
+
+	DefaultBaseURL = "https://dt.garage-trip.cz"
+)
+
+type Config struct {
+	APIKey  string `json:"apiKey,omitempty"`
+	BaseURL string `json:"baseUrl,omitempty"`
+
+	// RiskTolerance is the damage the bot is willing to take to save a single step.
+	RiskTolerance float64 `json:"riskTolerance,omitempty"`
+	// DamageTypes is how many damage types the bot builds its loadout around.
+	DamageTypes int `json:"damageTypes,omitempty"`
+
+	// Profile is the name of the build profile for spending attribute points.
+	Profile string `json:"profile,omitempty"`
+	// ProfileFile is a JSON file with additional build profiles.
+	ProfileFile string `json:"profileFile,omitempty"`
+	// Allocation is how attribute points are spent, see bot.Allocation.
+	Allocation string `json:"allocation,omitempty"`
+	// MemoryFile keeps the map memory across runs.
+	MemoryFile string `json:"memoryFile,omitempty"`
+}
+
+// Load builds the configuration from the config file, environment and flags.
+// Later sources override earlier ones, empty values are ignored.
+// The API key may be missing, commands talking to the server have to check it.
+func Load(path string, flags Config) (Config, error) {
+	cfg := Config{
+		BaseURL: DefaultBaseURL,
+	}
+
+	if path == "" {
+		path = os.Getenv(EnvConfig)
+	}
+	if path != "" {
+		file, err := readFile(path)
+		if err != nil {
+			return Config{}, err
+		}
+		cfg.merge(file)
+	}
+
+	cfg.merge(Config{
+		APIKey:  os.Getenv(EnvAPIKey),
+		BaseURL: os.Getenv(EnvBaseURL),
+	})
+	cfg.merge(flags)
+
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+
+	return cfg, nil
+}
+
+func readFile(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("read config: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+func (c *Config) merge(other Config) {
+	if other.APIKey != "" {
+		c.APIKey = other.APIKey
+	}
+	if other.BaseURL != "" {
+		c.BaseURL = other.BaseURL
+	}
+	if other.RiskTolerance != 0 {
+		c.RiskTolerance = other.RiskTolerance
+	}
+	if other.DamageTypes != 0 {
+		c.DamageTypes = other.DamageTypes
+	}
+	if other.Profile != "" {
+		c.Profile = other.Profile
+	}
+	if other.ProfileFile != "" {
+		c.ProfileFile = other.ProfileFile
+	}
+	if other.Allocation != "" {
+		c.Allocation = other.Allocation
+	}
+	if other.MemoryFile != "" {
+		c.MemoryFile = other.MemoryFile
+	}
+}
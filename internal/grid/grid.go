@@ -0,0 +1,227 @@
+// Package grid finds paths over a level's tile map.
+package grid
+
+import (
+	"container/heap"
+	"math"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+)
+
+type Position = swagger.DungeonsandtrollsPosition
+
+type tile struct {
+	wall     bool
+	door     bool
+	occupied bool
+}
+
+// Grid is a snapshot of a level's walkable tiles.
+// Tiles without any map object are considered free.
+type Grid struct {
+	Width  int32
+	Height int32
+	tiles  []tile
+}
+
+func New(level swagger.DungeonsandtrollsLevel) *Grid {
+	g := &Grid{
+		Width:  level.Width,
+		Height: level.Height,
+		tiles:  make([]tile, level.Width*level.Height),
+	}
+	for _, object := range level.Objects {
+		if object.Position == nil || !g.Inside(*object.Position) {
+			continue
+		}
+		t := &g.tiles[g.index(*object.Position)]
+		t.wall = t.wall || object.IsWall
+		t.door = t.door || object.IsDoor
+		t.occupied = t.occupied || len(object.Monsters) > 0
+	}
+	return g
+}
+
+func (g *Grid) index(pos Position) int {
+	return int(pos.PositionY*g.Width + pos.PositionX)
+}
+
+func (g *Grid) Inside(pos Position) bool {
+	return pos.PositionX >= 0 && pos.PositionY >= 0 && pos.PositionX < g.Width && pos.PositionY < g.Height
+}
+
+// Walkable reports whether pos can be walked through, ignoring monsters.
+func (g *Grid) Walkable(pos Position) bool {
+	return g.Inside(pos) && !g.tiles[g.index(pos)].wall
+}
+
+func (g *Grid) Door(pos Position) bool {
+	return g.Inside(pos) && g.tiles[g.index(pos)].door
+}
+
+// Occupied reports whether there is a monster on pos.
+func (g *Grid) Occupied(pos Position) bool {
+	return g.Inside(pos) && g.tiles[g.index(pos)].occupied
+}
+
+// Cost returns the extra cost of stepping on a tile on top of the cost of a single step.
+// Infinite cost makes the tile impassable.
+type Cost func(pos Position) float64
+
+// Sum combines costs by adding them up.
+func Sum(costs ...Cost) Cost {
+	return func(pos Position) float64 {
+		total := 0.0
+		for _, cost := range costs {
+			if cost != nil {
+				total += cost(pos)
+			}
+		}
+		return total
+	}
+}
+
+var directions = []Position{
+	{PositionX: 1},
+	{PositionX: -1},
+	{PositionY: 1},
+	{PositionY: -1},
+}
+
+func Neighbors(pos Position) []Position {
+	res := make([]Position, len(directions))
+	for i, dir := range directions {
+		res[i] = Position{
+			PositionX: pos.PositionX + dir.PositionX,
+			PositionY: pos.PositionY + dir.PositionY,
+		}
+	}
+	return res
+}
+
+func abs(i int32) int32 {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
+// Distance is the Manhattan distance of two positions.
+func Distance(a, b Position) int {
+	return int(abs(a.PositionX-b.PositionX) + abs(a.PositionY-b.PositionY))
+}
+
+// Path is a sequence of steps, not including the starting position.
+type Path struct {
+	Steps []Position
+	Cost  float64
+}
+
+// Turns estimates the number of ticks to walk the path.
+func (p Path) Turns() int {
+	return len(p.Steps)
+}
+
+func (p Path) Goal() Position {
+	return p.Steps[len(p.Steps)-1]
+}
+
+// Path finds the cheapest path from `from` to `to` with A*.
+// Tiles occupied by monsters are impassable, except for the goal itself.
+// The returned path is empty if from equals to.
+func (g *Grid) Path(from, to Position, cost Cost) (Path, bool) {
+	if !g.Inside(from) || !g.Walkable(to) {
+		return Path{}, false
+	}
+	if from == to {
+		return Path{}, true
+	}
+
+	prev := map[Position]Position{}
+	best := map[Position]float64{from: 0}
+	open := &queue{}
+	heap.Push(open, item{pos: from, priority: float64(Distance(from, to))})
+
+	for open.Len() > 0 {
+		current := heap.Pop(open).(item)
+		if current.pos == to {
+			return g.path(prev, from, to, best[to]), true
+		}
+		if current.priority > best[current.pos]+float64(Distance(current.pos, to)) {
+			// Stale entry, a cheaper route was found after it was queued.
+			continue
+		}
+
+		for _, next := range Neighbors(current.pos) {
+			if !g.Walkable(next) || (next != to && g.Occupied(next)) {
+				continue
+			}
+			step := 1.0
+			if cost != nil {
+				step += cost(next)
+			}
+			if math.IsInf(step, 1) {
+				continue
+			}
+			c := best[current.pos] + step
+			if b, ok := best[next]; ok && b <= c {
+				continue
+			}
+			best[next] = c
+			prev[next] = current.pos
+			heap.Push(open, item{pos: next, priority: c + float64(Distance(next, to))})
+		}
+	}
+
+	return Path{}, false
+}
+
+func (g *Grid) path(prev map[Position]Position, from, to Position, cost float64) Path {
+	var steps []Position
+	for pos := to; pos != from; pos = prev[pos] {
+		steps = append(steps, pos)
+	}
+	for i, j := 0, len(steps)-1; i < j; i, j = i+1, j-1 {
+		steps[i], steps[j] = steps[j], steps[i]
+	}
+	return Path{Steps: steps, Cost: cost}
+}
+
+// Waypoint returns the end of the first straight segment of the path.
+// The shortest route there is unique, so the server walks it exactly as planned.
+func (p Path) Waypoint(from Position) Position {
+	if len(p.Steps) == 0 {
+		return from
+	}
+
+	dir := Position{
+		PositionX: p.Steps[0].PositionX - from.PositionX,
+		PositionY: p.Steps[0].PositionY - from.PositionY,
+	}
+	waypoint := p.Steps[0]
+	for _, pos := range p.Steps[1:] {
+		if pos.PositionX-waypoint.PositionX != dir.PositionX || pos.PositionY-waypoint.PositionY != dir.PositionY {
+			break
+		}
+		waypoint = pos
+	}
+	return waypoint
+}
+
+type item struct {
+	pos      Position
+	priority float64
+}
+
+type queue []item
+
+func (q queue) Len() int           { return len(q) }
+func (q queue) Less(i, j int) bool { return q[i].priority < q[j].priority }
+func (q queue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *queue) Push(x any)        { *q = append(*q, x.(item)) }
+func (q *queue) Pop() any {
+	old := *q
+	it := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return it
+}
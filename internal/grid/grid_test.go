@@ -0,0 +1,161 @@
+package grid
+
+import (
+	"strings"
+	"testing"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+)
+
+// parse builds a level from a drawing: '#' wall, 'D' door, 'M' monster,
+// 'S' and 'G' are returned as start and goal.
+func parse(drawing string) (level swagger.DungeonsandtrollsLevel, start, goal Position) {
+	rows := strings.Split(strings.TrimSpace(drawing), "\n")
+	level.Height = int32(len(rows))
+	for y, row := range rows {
+		row = strings.TrimSpace(row)
+		level.Width = int32(len(row))
+		for x, c := range row {
+			pos := Position{PositionX: int32(x), PositionY: int32(y)}
+			object := swagger.DungeonsandtrollsMapObjects{Position: &pos}
+			switch c {
+			case '#':
+				object.IsWall = true
+			case 'D':
+				object.IsDoor = true
+			case 'M':
+				object.Monsters = []swagger.DungeonsandtrollsMonster{{Id: "m"}}
+			case 'S':
+				start = pos
+			case 'G':
+				goal = pos
+			}
+			level.Objects = append(level.Objects, object)
+		}
+	}
+	return level, start, goal
+}
+
+// avoid penalizes tiles next to or on any of the positions.
+func avoid(positions []Position, penalty float64) Cost {
+	return func(pos Position) float64 {
+		for _, p := range positions {
+			if Distance(p, pos) <= 1 {
+				return penalty
+			}
+		}
+		return 0
+	}
+}
+
+func TestPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		drawing string
+		cost    func(level swagger.DungeonsandtrollsLevel) Cost
+		turns   int
+		found   bool
+	}{
+		{
+			name: "straight",
+			drawing: `
+				#######
+				#S...G#
+				#######`,
+			turns: 4,
+			found: true,
+		},
+		{
+			name: "through door",
+			drawing: `
+				#######
+				#S.#..#
+				#..D.G#
+				#######`,
+			turns: 5,
+			found: true,
+		},
+		{
+			name: "walled off",
+			drawing: `
+				#######
+				#S.#.G#
+				#######`,
+			found: false,
+		},
+		{
+			name: "around monster",
+			drawing: `
+				#######
+				#S.M.G#
+				#.....#
+				#######`,
+			turns: 6,
+			found: true,
+		},
+		{
+			name: "avoid monster",
+			drawing: `
+				#######
+				#S...G#
+				#.....#
+				#.....#
+				#..M..#
+				#######`,
+			cost: func(level swagger.DungeonsandtrollsLevel) Cost {
+				return avoid([]Position{{PositionX: 3, PositionY: 1}}, 10)
+			},
+			turns: 8,
+			found: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level, start, goal := parse(tt.drawing)
+			var cost Cost
+			if tt.cost != nil {
+				cost = tt.cost(level)
+			}
+
+			path, found := New(level).Path(start, goal, cost)
+			if found != tt.found {
+				t.Fatalf("found = %v, want %v", found, tt.found)
+			}
+			if !found {
+				return
+			}
+			if path.Turns() != tt.turns {
+				t.Errorf("turns = %d, want %d: %+v", path.Turns(), tt.turns, path.Steps)
+			}
+			if path.Goal() != goal {
+				t.Errorf("path ends at %+v, want %+v", path.Goal(), goal)
+			}
+		})
+	}
+}
+
+func TestWaypoint(t *testing.T) {
+	level, start, goal := parse(`
+		#######
+		#S...G#
+		#.###.#
+		#.....#
+		#######`)
+	g := New(level)
+	danger := Position{PositionX: 3, PositionY: 1}
+
+	path, _ := g.Path(start, goal, avoid([]Position{danger}, 10))
+	if path.Turns() != 8 {
+		t.Fatalf("expected the detour through the bottom corridor: %+v", path.Steps)
+	}
+	want := Position{PositionX: 1, PositionY: 3}
+	if got := path.Waypoint(start); got != want {
+		t.Errorf("waypoint = %+v, want %+v", got, want)
+	}
+
+	path, _ = g.Path(start, goal, nil)
+	if got := path.Waypoint(start); got != goal {
+		t.Errorf("waypoint = %+v, want goal %+v", got, goal)
+	}
+}
@@ -0,0 +1,95 @@
+// Package record stores game sessions as gzip compressed JSON lines
+// and replays them through a strategy.
+package record
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+	"github.com/liennie/gdt/internal/bot"
+)
+
+// Entry is a single tick of a session.
+// State is missing when the game state could not be fetched.
+// The first entry may instead hold the map memory the session started with.
+type Entry struct {
+	Tick    int32                                   `json:"tick"`
+	State   *swagger.DungeonsandtrollsGameState     `json:"state,omitempty"`
+	Command *swagger.DungeonsandtrollsCommandsBatch `json:"command,omitempty"`
+	Error   string                                  `json:"error,omitempty"`
+	Memory  *bot.Memory                             `json:"memory,omitempty"`
+}
+
+type Recorder struct {
+	file *os.File
+	gz   *gzip.Writer
+	enc  *json.Encoder
+}
+
+func Create(path string) (*Recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("create recording: %w", err)
+	}
+	gz := gzip.NewWriter(file)
+	return &Recorder{
+		file: file,
+		gz:   gz,
+		enc:  json.NewEncoder(gz),
+	}, nil
+}
+
+func (r *Recorder) Record(entry Entry) error {
+	if err := r.enc.Encode(entry); err != nil {
+		return fmt.Errorf("record tick %d: %w", entry.Tick, err)
+	}
+	// Flush every entry so a crashed or killed bot still leaves a readable recording.
+	return r.gz.Flush()
+}
+
+func (r *Recorder) Close() error {
+	if err := r.gz.Close(); err != nil {
+		r.file.Close()
+		return err
+	}
+	return r.file.Close()
+}
+
+type Reader struct {
+	file *os.File
+	gz   *gzip.Reader
+	dec  *json.Decoder
+}
+
+func Open(path string) (*Reader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open recording: %w", err)
+	}
+	gz, err := gzip.NewReader(bufio.NewReader(file))
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("open recording %s: %w", path, err)
+	}
+	return &Reader{
+		file: file,
+		gz:   gz,
+		dec:  json.NewDecoder(gz),
+	}, nil
+}
+
+// Next returns the next entry or io.EOF at the end of the recording.
+func (r *Reader) Next() (Entry, error) {
+	var entry Entry
+	err := r.dec.Decode(&entry)
+	return entry, err
+}
+
+func (r *Reader) Close() error {
+	r.gz.Close()
+	return r.file.Close()
+}
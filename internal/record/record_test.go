@@ -0,0 +1,165 @@
+package record
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+	"github.com/liennie/gdt/internal/bot"
+)
+
+func TestReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl.gz")
+
+	recorder, err := Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	move := func(x int32) *swagger.DungeonsandtrollsCommandsBatch {
+		return &swagger.DungeonsandtrollsCommandsBatch{Move: &swagger.DungeonsandtrollsPosition{PositionX: x}}
+	}
+	entries := []Entry{
+		{Tick: 1, State: &swagger.DungeonsandtrollsGameState{Tick: 1}, Command: move(1)},
+		{Error: "connection refused"},
+		{Tick: 2, State: &swagger.DungeonsandtrollsGameState{Tick: 2}, Command: move(2), Error: "400 Bad Request"},
+		{Tick: 3, State: &swagger.DungeonsandtrollsGameState{Tick: 3}},
+	}
+	for _, entry := range entries {
+		if err := recorder.Record(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	// Agrees with the recording except on tick 2.
+	strategy := bot.StrategyFunc(func(state swagger.DungeonsandtrollsGameState) *swagger.DungeonsandtrollsCommandsBatch {
+		if state.Tick == 2 {
+			return &swagger.DungeonsandtrollsCommandsBatch{Yell: &swagger.DungeonsandtrollsMessage{Text: "hi"}}
+		}
+		if state.Tick == 3 {
+			return nil
+		}
+		return move(state.Tick)
+	})
+
+	var diffs []Diff
+	summary, err := Replay(reader, strategy, func(diff Diff) {
+		diffs = append(diffs, diff)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := (Summary{Ticks: 3, Diffs: 1}); summary != want {
+		t.Errorf("summary = %+v, want %+v", summary, want)
+	}
+	if len(diffs) != 1 {
+		t.Fatalf("got %d diffs, want 1", len(diffs))
+	}
+	if diffs[0].Tick != 2 || !reflect.DeepEqual(diffs[0].Fields, []string{"move", "yell"}) {
+		t.Errorf("diff = %+v", diffs[0])
+	}
+}
+
+// TestReplayAssignedPoints replays a recording of the bot itself, which must decide the same
+// when assigning attribute points.
+func TestReplayAssignedPoints(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl.gz")
+	profiles, err := bot.LoadProfiles("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	play := func() bot.Default { return bot.Default{Profile: profiles[bot.DefaultProfile]} }
+
+	recorder, err := Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := swagger.DungeonsandtrollsGameState{
+		Tick: 1,
+		Character: &swagger.DungeonsandtrollsCharacter{
+			SkillPoints:   10,
+			Attributes:    &swagger.DungeonsandtrollsAttributes{},
+			MaxAttributes: &swagger.DungeonsandtrollsAttributes{},
+		},
+		CurrentPosition: &swagger.DungeonsandtrollsPosition{},
+	}
+	command := play().Decide(state)
+	if command == nil || command.AssignSkillPoints == nil {
+		t.Fatalf("command %+v, want assigning points", command)
+	}
+	if err := recorder.Record(Entry{Tick: state.Tick, State: &state, Command: command}); err != nil {
+		t.Fatal(err)
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	summary, err := Replay(reader, play(), func(diff Diff) {
+		t.Errorf("diff = %+v", diff)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Summary{Ticks: 1}); summary != want {
+		t.Errorf("summary = %+v, want %+v", summary, want)
+	}
+}
+
+func TestReplayMemory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl.gz")
+	stairs := swagger.DungeonsandtrollsPosition{PositionX: 4}
+	state := func(tick int32, objects ...swagger.DungeonsandtrollsMapObjects) *swagger.DungeonsandtrollsGameState {
+		return &swagger.DungeonsandtrollsGameState{
+			Tick:            tick,
+			CurrentPosition: &swagger.DungeonsandtrollsPosition{},
+			Map_: &swagger.DungeonsandtrollsMap{Levels: []swagger.DungeonsandtrollsLevel{
+				{Width: 5, Height: 1, Objects: objects},
+			}},
+		}
+	}
+	// The session started knowing the stairs it doesn't see.
+	started := bot.NewMemory()
+	started.Observe(*state(1, swagger.DungeonsandtrollsMapObjects{Position: &stairs, IsStairs: true}))
+
+	recorder, err := Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := recorder.Record(Entry{Memory: started}); err != nil {
+		t.Fatal(err)
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	play := bot.Default{Memory: bot.NewMemory()}
+	if _, err := Replay(reader, play, func(Diff) {}); err != nil {
+		t.Fatal(err)
+	}
+	recalled := play.Memory.Recall(*state(2))
+	if objects := recalled.Map_.Levels[0].Objects; len(objects) != 1 || !objects[0].IsStairs {
+		t.Errorf("recalled %+v, want the stairs", objects)
+	}
+}
@@ -0,0 +1,105 @@
+package record
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+	"github.com/liennie/gdt/internal/bot"
+)
+
+// Diff is a tick where the strategy decided differently than in the recording.
+type Diff struct {
+	Tick     int32
+	Recorded *swagger.DungeonsandtrollsCommandsBatch
+	Replayed *swagger.DungeonsandtrollsCommandsBatch
+	// Fields lists the command fields that differ, e.g. "move" or "skill".
+	Fields []string
+}
+
+type Summary struct {
+	Ticks int
+	Diffs int
+}
+
+// Replay feeds every recorded state through the strategy and calls report for each differing decision.
+// A Default with a Memory starts from the memory recorded with the session, if any.
+func Replay(r *Reader, strategy bot.Strategy, report func(Diff)) (Summary, error) {
+	var summary Summary
+	for {
+		entry, err := r.Next()
+		// A recording of a killed bot ends in the middle of the gzip stream.
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return summary, nil
+		}
+		if err != nil {
+			return summary, err
+		}
+		if entry.Memory != nil {
+			if play, ok := strategy.(bot.Default); ok && play.Memory != nil {
+				*play.Memory = *entry.Memory
+			}
+		}
+		if entry.State == nil {
+			continue
+		}
+
+		summary.Ticks++
+		command := strategy.Decide(*entry.State)
+
+		fields, err := diffFields(entry.Command, command)
+		if err != nil {
+			return summary, err
+		}
+		if len(fields) > 0 {
+			summary.Diffs++
+			report(Diff{
+				Tick:     entry.Tick,
+				Recorded: entry.Command,
+				Replayed: command,
+				Fields:   fields,
+			})
+		}
+	}
+}
+
+func diffFields(a, b *swagger.DungeonsandtrollsCommandsBatch) ([]string, error) {
+	am, err := fields(a)
+	if err != nil {
+		return nil, err
+	}
+	bm, err := fields(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var diff []string
+	for name, av := range am {
+		if !bytes.Equal(av, bm[name]) {
+			diff = append(diff, name)
+		}
+	}
+	for name := range bm {
+		if _, ok := am[name]; !ok {
+			diff = append(diff, name)
+		}
+	}
+	sort.Strings(diff)
+	return diff, nil
+}
+
+func fields(command *swagger.DungeonsandtrollsCommandsBatch) (map[string]json.RawMessage, error) {
+	if command == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(command)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]json.RawMessage
+	err = json.Unmarshal(data, &m)
+	return m, err
+}
@@ -0,0 +1,124 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"time"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+	"github.com/liennie/gdt/internal/bot"
+	"github.com/liennie/gdt/internal/record"
+)
+
+type Runner struct {
+	Client   *swagger.APIClient
+	Strategy bot.Strategy
+
+	// Delay is how long to wait after an error or an empty command.
+	Delay time.Duration
+
+	// Recorder, if set, stores every tick of the session.
+	Recorder *record.Recorder
+}
+
+func New(client *swagger.APIClient, strategy bot.Strategy) *Runner {
+	return &Runner{
+		Client:   client,
+		Strategy: strategy,
+		Delay:    time.Second,
+	}
+}
+
+// Run plays the game until ctx is done.
+func (r *Runner) Run(ctx context.Context) {
+	lastYell := ""
+	lastYellTick := int32(0)
+
+	for ctx.Err() == nil {
+		// Use the client to make API requests
+		gameResp, httpResp, err := r.Client.DungeonsAndTrollsApi.DungeonsAndTrollsGame(ctx, nil)
+		if err != nil {
+			log.Printf("HTTP Response: %+v\n", httpResp)
+			log.Print(err)
+			r.record(record.Entry{Error: errorText(err)})
+			r.wait(ctx)
+			continue
+		}
+		// fmt.Println("Response:", resp)
+		fmt.Println("Next tick ...")
+		command := r.Strategy.Decide(gameResp)
+		if respawner, ok := r.Strategy.(bot.Respawner); ok && respawner.Respawning() {
+			r.Respawn(ctx)
+		}
+		if command == nil {
+			r.record(record.Entry{Tick: gameResp.Tick, State: &gameResp})
+			r.wait(ctx)
+			continue
+		}
+		decided := *command
+
+		if command.Yell != nil {
+			if command.Yell.Text == lastYell && gameResp.Tick < lastYellTick+10 {
+				command.Yell = nil
+			} else {
+				lastYell = command.Yell.Text
+				lastYellTick = gameResp.Tick
+			}
+		}
+
+		bot.LogStruct(reflect.ValueOf(command), "Command")
+
+		_, httpResp, err = r.Client.DungeonsAndTrollsApi.DungeonsAndTrollsCommands(ctx, *command, nil)
+		entry := record.Entry{Tick: gameResp.Tick, State: &gameResp, Command: &decided}
+		if err != nil {
+			entry.Error = errorText(err)
+		}
+		r.record(entry)
+		if err != nil {
+			swaggerErr, ok := err.(swagger.GenericSwaggerError)
+			if ok {
+				log.Printf("Server error response: %s\n", swaggerErr.Body())
+			} else {
+				log.Printf("HTTP Response: %+v\n", httpResp)
+				log.Print(err)
+			}
+			r.wait(ctx)
+			continue
+		}
+	}
+}
+
+// Respawn respawns the character.
+func (r *Runner) Respawn(ctx context.Context) {
+	log.Println("Respawning ...")
+	_, httpResp, err := r.Client.DungeonsAndTrollsApi.DungeonsAndTrollsRespawn(ctx, struct{}{}, nil)
+	if err != nil {
+		log.Printf("HTTP Response: %+v\n", httpResp)
+		log.Print(err)
+	}
+}
+
+func (r *Runner) record(entry record.Entry) {
+	if r.Recorder == nil {
+		return
+	}
+	if err := r.Recorder.Record(entry); err != nil {
+		log.Print(err)
+	}
+}
+
+func (r *Runner) wait(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(r.Delay):
+	}
+}
+
+func errorText(err error) string {
+	if swaggerErr, ok := err.(swagger.GenericSwaggerError); ok {
+		return fmt.Sprintf("%s: %s", swaggerErr.Error(), swaggerErr.Body())
+	}
+	return err.Error()
+}
@@ -0,0 +1,292 @@
+package sim
+
+import (
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+)
+
+type skillSpec struct {
+	name       string
+	target     swagger.SkillTarget
+	damageType swagger.DungeonsandtrollsDamageType
+	cost       swagger.DungeonsandtrollsAttributes
+	range_     swagger.DungeonsandtrollsAttributes
+	radius     *swagger.DungeonsandtrollsAttributes
+	damage     *swagger.DungeonsandtrollsAttributes
+	caster     *swagger.DungeonsandtrollsSkillAttributes
+	targetFx   *swagger.DungeonsandtrollsSkillAttributes
+	stun       bool
+	// cooldown is the number of ticks between two casts. The API doesn't expose it, like on the real server.
+	cooldown int32
+}
+
+type itemSpec struct {
+	id           string
+	name         string
+	slot         swagger.DungeonsandtrollsItemType
+	price        int32
+	requirements swagger.DungeonsandtrollsAttributes
+	attributes   swagger.DungeonsandtrollsAttributes
+	skills       []skillSpec
+}
+
+var catalog = []itemSpec{
+	{
+		id: "fire-staff", name: "Fire Staff", slot: swagger.MAIN_HAND_DungeonsandtrollsItemType, price: 200,
+		attributes: swagger.DungeonsandtrollsAttributes{Intelligence: 2},
+		skills: []skillSpec{{
+			name: "Fireball", target: swagger.CHARACTER_SkillTarget, damageType: swagger.FIRE_DungeonsandtrollsDamageType,
+			cost: swagger.DungeonsandtrollsAttributes{Mana: 4}, range_: swagger.DungeonsandtrollsAttributes{Constant: 4},
+			damage: &swagger.DungeonsandtrollsAttributes{Intelligence: 1, Constant: 6},
+		}},
+	},
+	{
+		id: "flame-wand", name: "Flame Wand", slot: swagger.MAIN_HAND_DungeonsandtrollsItemType, price: 120,
+		skills: []skillSpec{{
+			name: "Spark", target: swagger.CHARACTER_SkillTarget, damageType: swagger.FIRE_DungeonsandtrollsDamageType,
+			cost: swagger.DungeonsandtrollsAttributes{Mana: 2}, range_: swagger.DungeonsandtrollsAttributes{Constant: 3},
+			damage: &swagger.DungeonsandtrollsAttributes{Intelligence: 0.6, Constant: 3},
+		}},
+	},
+	{
+		id: "storm-rod", name: "Storm Rod", slot: swagger.MAIN_HAND_DungeonsandtrollsItemType, price: 220,
+		skills: []skillSpec{{
+			name: "Chain Lightning", target: swagger.CHARACTER_SkillTarget, damageType: swagger.ELECTRIC_DungeonsandtrollsDamageType,
+			cost: swagger.DungeonsandtrollsAttributes{Mana: 5}, range_: swagger.DungeonsandtrollsAttributes{Constant: 4},
+			damage: &swagger.DungeonsandtrollsAttributes{Intelligence: 1, Constant: 5},
+		}, {
+			name: "Thunderclap", target: swagger.POSITION_SkillTarget, damageType: swagger.ELECTRIC_DungeonsandtrollsDamageType,
+			cost: swagger.DungeonsandtrollsAttributes{Mana: 8}, range_: swagger.DungeonsandtrollsAttributes{Constant: 4},
+			radius:   &swagger.DungeonsandtrollsAttributes{Constant: 1},
+			damage:   &swagger.DungeonsandtrollsAttributes{Intelligence: 0.8, Constant: 2},
+			stun:     true,
+			cooldown: 3,
+		}},
+	},
+	{
+		id: "iron-sword", name: "Iron Sword", slot: swagger.MAIN_HAND_DungeonsandtrollsItemType, price: 150,
+		requirements: swagger.DungeonsandtrollsAttributes{Strength: 6},
+		skills: []skillSpec{{
+			name: "Slash", target: swagger.CHARACTER_SkillTarget, damageType: swagger.SLASH_DungeonsandtrollsDamageType,
+			cost: swagger.DungeonsandtrollsAttributes{Stamina: 4}, range_: swagger.DungeonsandtrollsAttributes{Constant: 1},
+			damage: &swagger.DungeonsandtrollsAttributes{Strength: 1.5, Constant: 5},
+		}},
+	},
+	{
+		id: "inferno-staff", name: "Inferno Staff", slot: swagger.MAIN_HAND_DungeonsandtrollsItemType, price: 900,
+		requirements: swagger.DungeonsandtrollsAttributes{Intelligence: 15},
+		attributes:   swagger.DungeonsandtrollsAttributes{Intelligence: 5},
+		skills: []skillSpec{{
+			name: "Inferno", target: swagger.CHARACTER_SkillTarget, damageType: swagger.FIRE_DungeonsandtrollsDamageType,
+			cost: swagger.DungeonsandtrollsAttributes{Mana: 8}, range_: swagger.DungeonsandtrollsAttributes{Constant: 5},
+			damage: &swagger.DungeonsandtrollsAttributes{Intelligence: 2, Constant: 10},
+		}},
+	},
+	{
+		id: "meditation-orb", name: "Meditation Orb", slot: swagger.OFF_HAND_DungeonsandtrollsItemType, price: 100,
+		skills: []skillSpec{{
+			name: "Meditate", target: swagger.NONE_SkillTarget,
+			caster: &swagger.DungeonsandtrollsSkillAttributes{
+				Stamina: &swagger.DungeonsandtrollsAttributes{Constitution: 2},
+				Mana:    &swagger.DungeonsandtrollsAttributes{Willpower: 2},
+			},
+		}},
+	},
+	{
+		id: "wooden-shield", name: "Wooden Shield", slot: swagger.OFF_HAND_DungeonsandtrollsItemType, price: 80,
+		attributes: swagger.DungeonsandtrollsAttributes{SlashResist: 2, PierceResist: 2},
+	},
+	{
+		id: "healers-circlet", name: "Healer's Circlet", slot: swagger.HEAD_DungeonsandtrollsItemType, price: 100,
+		skills: []skillSpec{{
+			name: "Mend", target: swagger.CHARACTER_SkillTarget,
+			cost: swagger.DungeonsandtrollsAttributes{Mana: 6},
+			targetFx: &swagger.DungeonsandtrollsSkillAttributes{
+				Life: &swagger.DungeonsandtrollsAttributes{Willpower: 3, Constant: 5},
+			},
+		}},
+	},
+	{
+		id: "leather-cap", name: "Leather Cap", slot: swagger.HEAD_DungeonsandtrollsItemType, price: 40,
+		attributes: swagger.DungeonsandtrollsAttributes{SlashResist: 1, FireResist: 1},
+	},
+	{
+		id: "robe", name: "Robe", slot: swagger.BODY_DungeonsandtrollsItemType, price: 60,
+		attributes: swagger.DungeonsandtrollsAttributes{Mana: 10, FireResist: 1},
+	},
+	{
+		id: "chainmail", name: "Chainmail", slot: swagger.BODY_DungeonsandtrollsItemType, price: 160,
+		requirements: swagger.DungeonsandtrollsAttributes{Strength: 6},
+		attributes:   swagger.DungeonsandtrollsAttributes{SlashResist: 4, PierceResist: 3},
+	},
+	{
+		id: "leggings", name: "Leggings", slot: swagger.LEGS_DungeonsandtrollsItemType, price: 50,
+		attributes: swagger.DungeonsandtrollsAttributes{Stamina: 10, SlashResist: 1},
+	},
+	{
+		id: "fire-amulet", name: "Amulet of Fire", slot: swagger.NECK_DungeonsandtrollsItemType, price: 90,
+		attributes: swagger.DungeonsandtrollsAttributes{Intelligence: 2, FireResist: 2},
+	},
+	{
+		id: "pendant", name: "Pendant", slot: swagger.NECK_DungeonsandtrollsItemType, price: 40,
+		attributes: swagger.DungeonsandtrollsAttributes{Life: 10},
+	},
+}
+
+func shopItems() []swagger.DungeonsandtrollsItem {
+	items := make([]swagger.DungeonsandtrollsItem, len(catalog))
+	for i, spec := range catalog {
+		items[i] = spec.item()
+	}
+	return items
+}
+
+func (spec itemSpec) item() swagger.DungeonsandtrollsItem {
+	slot := spec.slot
+	requirements := spec.requirements
+	attributes := spec.attributes
+
+	item := swagger.DungeonsandtrollsItem{
+		Id:           spec.id,
+		Name:         spec.name,
+		Slot:         &slot,
+		Price:        spec.price,
+		Requirements: &requirements,
+		Attributes:   &attributes,
+	}
+	for i, skill := range spec.skills {
+		item.Skills = append(item.Skills, skill.skill(spec.id, i))
+	}
+	return item
+}
+
+func (spec skillSpec) skill(itemID string, i int) swagger.DungeonsandtrollsSkill {
+	target := spec.target
+	damageType := spec.damageType
+	if damageType == "" {
+		damageType = swagger.NONE_DungeonsandtrollsDamageType
+	}
+	cost := spec.cost
+	range_ := spec.range_
+
+	skill := swagger.DungeonsandtrollsSkill{
+		Id:           skillID(itemID, i),
+		Name:         spec.name,
+		Target:       &target,
+		Cost:         &cost,
+		Range_:       &range_,
+		Radius:       spec.radius,
+		DamageAmount: spec.damage,
+		DamageType:   &damageType,
+		Flags:        &swagger.DungeonsandtrollsSkillGenericFlags{},
+	}
+	if spec.caster != nil {
+		skill.CasterEffects = &swagger.DungeonsandtrollsSkillEffect{Attributes: spec.caster}
+	}
+	if spec.targetFx != nil || spec.stun {
+		skill.TargetEffects = &swagger.DungeonsandtrollsSkillEffect{
+			Attributes: spec.targetFx,
+			Flags:      &swagger.DungeonsandtrollsSkillSpecificFlags{Stun: spec.stun},
+		}
+	}
+	return skill
+}
+
+func skillID(itemID string, i int) string {
+	return itemID + "-skill-" + string(rune('a'+i))
+}
+
+func skillCooldown(id string) int32 {
+	for _, spec := range catalog {
+		for i, skill := range spec.skills {
+			if skillID(spec.id, i) == id {
+				return skill.cooldown
+			}
+		}
+	}
+	return 0
+}
+
+func eval(attrs swagger.DungeonsandtrollsAttributes, coefficients *swagger.DungeonsandtrollsAttributes) float32 {
+	if coefficients == nil {
+		return 0
+	}
+	c := coefficients
+	return attrs.Strength*c.Strength +
+		attrs.Dexterity*c.Dexterity +
+		attrs.Intelligence*c.Intelligence +
+		attrs.Willpower*c.Willpower +
+		attrs.Constitution*c.Constitution +
+		attrs.SlashResist*c.SlashResist +
+		attrs.PierceResist*c.PierceResist +
+		attrs.FireResist*c.FireResist +
+		attrs.PoisonResist*c.PoisonResist +
+		attrs.ElectricResist*c.ElectricResist +
+		attrs.Life*c.Life +
+		attrs.Stamina*c.Stamina +
+		attrs.Mana*c.Mana +
+		c.Constant
+}
+
+func add(a, b swagger.DungeonsandtrollsAttributes) swagger.DungeonsandtrollsAttributes {
+	return swagger.DungeonsandtrollsAttributes{
+		Strength:       a.Strength + b.Strength,
+		Dexterity:      a.Dexterity + b.Dexterity,
+		Intelligence:   a.Intelligence + b.Intelligence,
+		Willpower:      a.Willpower + b.Willpower,
+		Constitution:   a.Constitution + b.Constitution,
+		SlashResist:    a.SlashResist + b.SlashResist,
+		PierceResist:   a.PierceResist + b.PierceResist,
+		FireResist:     a.FireResist + b.FireResist,
+		PoisonResist:   a.PoisonResist + b.PoisonResist,
+		ElectricResist: a.ElectricResist + b.ElectricResist,
+		Life:           a.Life + b.Life,
+		Stamina:        a.Stamina + b.Stamina,
+		Mana:           a.Mana + b.Mana,
+		Constant:       a.Constant + b.Constant,
+	}
+}
+
+func satisfies(attrs swagger.DungeonsandtrollsAttributes, requirements *swagger.DungeonsandtrollsAttributes) bool {
+	if requirements == nil {
+		return true
+	}
+	r := requirements
+	return attrs.Strength >= r.Strength &&
+		attrs.Dexterity >= r.Dexterity &&
+		attrs.Intelligence >= r.Intelligence &&
+		attrs.Willpower >= r.Willpower &&
+		attrs.Constitution >= r.Constitution &&
+		attrs.SlashResist >= r.SlashResist &&
+		attrs.PierceResist >= r.PierceResist &&
+		attrs.FireResist >= r.FireResist &&
+		attrs.PoisonResist >= r.PoisonResist &&
+		attrs.ElectricResist >= r.ElectricResist &&
+		attrs.Life >= r.Life &&
+		attrs.Stamina >= r.Stamina &&
+		attrs.Mana >= r.Mana
+}
+
+// resist returns the resistance of attrs against the damage type.
+func resist(attrs swagger.DungeonsandtrollsAttributes, damageType swagger.DungeonsandtrollsDamageType) float32 {
+	switch damageType {
+	case swagger.SLASH_DungeonsandtrollsDamageType:
+		return attrs.SlashResist
+	case swagger.PIERCE_DungeonsandtrollsDamageType:
+		return attrs.PierceResist
+	case swagger.FIRE_DungeonsandtrollsDamageType:
+		return attrs.FireResist
+	case swagger.POISON_DungeonsandtrollsDamageType:
+		return attrs.PoisonResist
+	case swagger.ELECTRIC_DungeonsandtrollsDamageType:
+		return attrs.ElectricResist
+	}
+	return 0
+}
+
+// mitigate reduces damage by the target's resistance. Ten points of resistance halve the damage taken.
+func mitigate(damage, resistance float32) float32 {
+	if resistance <= 0 {
+		return damage
+	}
+	return damage * 10 / (10 + resistance)
+}
@@ -0,0 +1,362 @@
+package sim
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+)
+
+const (
+	aggroRange   = 6
+	regenPerTick = 1
+	dropChance   = 0.3
+)
+
+// apply executes the commands of a single tick. Commands after a failing one are skipped.
+func (s *Server) apply(batch swagger.DungeonsandtrollsCommandsBatch) error {
+	if batch.AssignSkillPoints != nil {
+		if err := s.assignSkillPoints(*batch.AssignSkillPoints); err != nil {
+			return err
+		}
+	}
+	if batch.Buy != nil {
+		if err := s.buy(batch.Buy.Ids); err != nil {
+			return err
+		}
+	}
+	if batch.PickUp != nil {
+		if err := s.pickUp(batch.PickUp.Id); err != nil {
+			return err
+		}
+	}
+	if batch.Skill != nil {
+		if err := s.useSkill(*batch.Skill); err != nil {
+			return err
+		}
+	}
+	if batch.Move != nil {
+		s.move(*batch.Move)
+	}
+	return nil
+}
+
+func (s *Server) assignSkillPoints(points swagger.DungeonsandtrollsAttributes) error {
+	total := float32(0)
+	for _, v := range []float32{
+		points.Strength, points.Dexterity, points.Intelligence, points.Willpower, points.Constitution,
+		points.SlashResist, points.PierceResist, points.FireResist, points.PoisonResist, points.ElectricResist,
+		points.Life, points.Stamina, points.Mana,
+	} {
+		if v < 0 {
+			return errors.New("cannot assign negative skill points")
+		}
+		total += v
+	}
+	if points.Constant != 0 {
+		return errors.New("cannot assign skill points to constant")
+	}
+	if total > s.char.skillPoints+0.001 {
+		return fmt.Errorf("not enough skill points: have %.2f, assigning %.2f", s.char.skillPoints, total)
+	}
+
+	s.char.skillPoints -= total
+	s.char.base = add(s.char.base, points)
+	s.char.life += points.Life
+	s.char.stamina += points.Stamina
+	s.char.mana += points.Mana
+	return nil
+}
+
+func (s *Server) buy(ids []string) error {
+	if s.char.level != 0 {
+		return errors.New("can only buy items on level 0")
+	}
+
+	equip := map[swagger.DungeonsandtrollsItemType]swagger.DungeonsandtrollsItem{}
+	for slot, item := range s.char.equip {
+		equip[slot] = item
+	}
+
+	total := int32(0)
+	bought := map[swagger.DungeonsandtrollsItemType]bool{}
+	for _, id := range ids {
+		item, ok := s.shopItem(id)
+		if !ok {
+			return fmt.Errorf("unknown item %q", id)
+		}
+		if bought[*item.Slot] {
+			return fmt.Errorf("buying multiple %s items", *item.Slot)
+		}
+		bought[*item.Slot] = true
+		total += item.Price
+		equip[*item.Slot] = item
+	}
+	if total > s.char.money {
+		return fmt.Errorf("not enough money: have %d, need %d", s.char.money, total)
+	}
+
+	attrs := s.char.base
+	for _, item := range equip {
+		attrs = add(attrs, *item.Attributes)
+	}
+	for _, item := range equip {
+		if !satisfies(attrs, item.Requirements) {
+			return fmt.Errorf("requirements of %q not met", item.Name)
+		}
+	}
+
+	s.char.money -= total
+	s.char.equip = equip
+	s.char.clamp()
+	return nil
+}
+
+func (s *Server) shopItem(id string) (swagger.DungeonsandtrollsItem, bool) {
+	for _, item := range s.shop {
+		if item.Id == id {
+			return item, true
+		}
+	}
+	return swagger.DungeonsandtrollsItem{}, false
+}
+
+func (s *Server) pickUp(id string) error {
+	l := s.level(s.char.level)
+	items := l.items[s.char.position]
+	for i, item := range items {
+		if item.Id != id {
+			continue
+		}
+		if !satisfies(s.char.attributes(), item.Requirements) {
+			return fmt.Errorf("requirements of %q not met", item.Name)
+		}
+		l.items[s.char.position] = append(items[:i:i], items[i+1:]...)
+		if old, ok := s.char.equip[*item.Slot]; ok {
+			l.items[s.char.position] = append(l.items[s.char.position], old)
+		}
+		s.char.equip[*item.Slot] = item
+		s.char.clamp()
+		return nil
+	}
+	return fmt.Errorf("no item %q on the current tile", id)
+}
+
+func (s *Server) equippedSkill(id string) (swagger.DungeonsandtrollsSkill, bool) {
+	for _, item := range s.char.equip {
+		for _, skill := range item.Skills {
+			if skill.Id == id {
+				return skill, true
+			}
+		}
+	}
+	return swagger.DungeonsandtrollsSkill{}, false
+}
+
+func (s *Server) useSkill(use swagger.DungeonsandtrollsSkillUse) error {
+	skill, ok := s.equippedSkill(use.SkillId)
+	if !ok {
+		return fmt.Errorf("skill %q is not equipped", use.SkillId)
+	}
+	if skill.Flags != nil && skill.Flags.Passive {
+		return fmt.Errorf("skill %q is passive", skill.Name)
+	}
+
+	if last, ok := s.char.casts[skill.Id]; ok && s.tick-last < skillCooldown(skill.Id) {
+		return fmt.Errorf("skill %q is on cooldown", skill.Name)
+	}
+
+	attrs := s.char.current()
+	if !satisfies(attrs, skill.Cost) {
+		return fmt.Errorf("not enough resources for %q", skill.Name)
+	}
+
+	l := s.level(s.char.level)
+	rang := int32(math.Trunc(float64(eval(attrs, skill.Range_))))
+
+	var center position
+	var target *monster
+	self := false
+
+	switch *skill.Target {
+	case swagger.CHARACTER_SkillTarget:
+		if use.TargetId == characterID {
+			self = true
+			center = s.char.position
+			break
+		}
+		target = l.monsterByID(use.TargetId)
+		if target == nil {
+			return fmt.Errorf("unknown target %q", use.TargetId)
+		}
+		center = target.position
+	case swagger.POSITION_SkillTarget:
+		if use.Position == nil {
+			return fmt.Errorf("skill %q needs a position", skill.Name)
+		}
+		center = *use.Position
+	default:
+		center = s.char.position
+	}
+
+	if manhattan(s.char.position, center) > rang {
+		return fmt.Errorf("target of %q is out of range", skill.Name)
+	}
+	if center != s.char.position && !l.lineOfSight(s.char.position, center) {
+		return fmt.Errorf("target of %q is not in line of sight", skill.Name)
+	}
+
+	s.char.life -= skill.Cost.Life
+	s.char.stamina -= skill.Cost.Stamina
+	s.char.mana -= skill.Cost.Mana
+	s.char.casts[skill.Id] = s.tick
+
+	var targets []*monster
+	switch {
+	case target != nil:
+		targets = append(targets, target)
+	case skill.Radius != nil:
+		radius := int32(math.Trunc(float64(eval(attrs, skill.Radius))))
+		for _, m := range l.monsters {
+			if manhattan(m.position, center) <= radius {
+				targets = append(targets, m)
+			}
+		}
+	}
+
+	damage := float32(0)
+	if skill.DamageAmount != nil && skill.DamageType != nil && *skill.DamageType != swagger.NONE_DungeonsandtrollsDamageType {
+		damage = eval(attrs, skill.DamageAmount)
+	}
+	for _, m := range targets {
+		amount := mitigate(damage, resist(*m.Attributes, *skill.DamageType))
+		if skill.TargetEffects != nil {
+			if skill.TargetEffects.Attributes != nil {
+				amount -= eval(attrs, skill.TargetEffects.Attributes.Life)
+			}
+			if skill.TargetEffects.Flags != nil && skill.TargetEffects.Flags.Stun {
+				m.stunned = 2
+			}
+		}
+		s.hurt(l, m, amount)
+	}
+
+	if self && skill.TargetEffects != nil && skill.TargetEffects.Attributes != nil {
+		s.char.life += eval(attrs, skill.TargetEffects.Attributes.Life)
+	}
+	if skill.CasterEffects != nil && skill.CasterEffects.Attributes != nil {
+		s.char.life += eval(attrs, skill.CasterEffects.Attributes.Life)
+		s.char.stamina += eval(attrs, skill.CasterEffects.Attributes.Stamina)
+		s.char.mana += eval(attrs, skill.CasterEffects.Attributes.Mana)
+	}
+	s.char.clamp()
+
+	return nil
+}
+
+func (s *Server) hurt(l *level, m *monster, amount float32) {
+	if amount <= 0 {
+		return
+	}
+	m.Attributes.Life -= amount
+	m.LastDamageTaken = 0
+	m.LifePercentage = max(m.Attributes.Life, 0) / m.MaxAttributes.Life * 100
+	if m.Attributes.Life > 0 {
+		return
+	}
+
+	l.removeMonster(m)
+	s.stats.Kills++
+	s.char.score += m.Score
+	s.char.money += 20 * l.number
+	s.char.skillPoints += 1
+
+	if s.rnd.Float64() < dropChance {
+		item := catalog[s.rnd.Intn(len(catalog))].item()
+		l.items[m.position] = append(l.items[m.position], item)
+	}
+}
+
+func (s *Server) move(to position) {
+	l := s.level(s.char.level)
+	next, ok := l.nextStep(s.char.position, to, func(pos position) bool {
+		return l.monsterAt(pos) != nil
+	})
+	if !ok || l.monsterAt(next) != nil {
+		return
+	}
+	s.char.position = next
+
+	if next == l.stairs {
+		s.enterLevel(l.number + 1)
+	} else if dest, ok := l.portals[next]; ok {
+		s.enterLevel(dest)
+	}
+}
+
+func (s *Server) enterLevel(n int32) {
+	s.char.moveTo(n, s.level(n).spawn)
+	s.char.score += float32(n)
+	s.stats.MaxLevel = max(s.stats.MaxLevel, n)
+}
+
+// advance moves the world to the next tick.
+func (s *Server) advance() {
+	s.tick++
+	s.events, s.nextEvents = s.nextEvents, nil
+
+	l := s.level(s.char.level)
+	attrs := s.char.current()
+	for _, m := range l.monsters {
+		m.LastDamageTaken++
+		if m.stunned > 0 {
+			m.stunned--
+			continue
+		}
+		dist := manhattan(m.position, s.char.position)
+		if dist > aggroRange {
+			continue
+		}
+
+		attacked := false
+		for _, item := range m.EquippedItems {
+			for _, skill := range item.Skills {
+				if dist > int32(eval(*m.Attributes, skill.Range_)) {
+					continue
+				}
+				damage := mitigate(eval(*m.Attributes, skill.DamageAmount), resist(attrs, *skill.DamageType))
+				s.char.life -= damage
+				s.char.damagedTick = s.tick
+				attacked = true
+			}
+		}
+		if attacked {
+			continue
+		}
+
+		next, ok := l.nextStep(m.position, s.char.position, func(pos position) bool {
+			return l.monsterAt(pos) != nil
+		})
+		if ok && next != s.char.position && l.monsterAt(next) == nil {
+			m.position = next
+		}
+	}
+
+	if s.char.life <= 0 {
+		s.stats.Deaths++
+		s.respawn()
+		return
+	}
+
+	if s.tick-s.char.damagedTick > 2 {
+		s.char.stamina += regenPerTick
+		s.char.mana += regenPerTick
+		s.char.clamp()
+	}
+}
+
+func (s *Server) respawn() {
+	s.char.moveTo(0, s.level(0).spawn)
+	s.char.refill()
+}
@@ -0,0 +1,160 @@
+// Package sim is an in-process stand-in for the game server.
+//
+// It implements the game, commands and respawn endpoints for a single
+// character on generated levels, so the bot can be played end-to-end
+// without the real server.
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"sync"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+)
+
+type Stats struct {
+	Tick     int32
+	Level    int32
+	MaxLevel int32
+	Kills    int
+	Deaths   int
+	Score    float32
+}
+
+type Server struct {
+	mu  sync.Mutex
+	rnd *rand.Rand
+	mux *http.ServeMux
+
+	tick   int32
+	idle   bool
+	levels map[int32]*level
+	shop   []swagger.DungeonsandtrollsItem
+	char   *character
+	stats  Stats
+
+	// events happened in the previous tick, nextEvents are collected during the current one.
+	events     []swagger.DungeonsandtrollsEvent
+	nextEvents []swagger.DungeonsandtrollsEvent
+}
+
+func New(seed int64) *Server {
+	s := &Server{
+		rnd:    rand.New(rand.NewSource(seed)),
+		mux:    http.NewServeMux(),
+		levels: map[int32]*level{},
+		shop:   shopItems(),
+		char:   newCharacter(),
+	}
+	s.char.moveTo(0, s.level(0).spawn)
+
+	s.mux.HandleFunc("/v1/game", s.handleGame)
+	s.mux.HandleFunc("/v1/commands", s.handleCommands)
+	s.mux.HandleFunc("/v1/respawn", s.handleRespawn)
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-API-Key") == "" {
+		writeError(w, http.StatusForbidden, "missing API key")
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.stats
+	stats.Tick = s.tick
+	stats.Level = s.char.level
+	stats.Score = s.char.score
+	return stats
+}
+
+func (s *Server) handleGame(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The real server ticks on its own, so a client that only polls still moves time forward.
+	if s.idle {
+		s.advance()
+	}
+	s.idle = true
+
+	writeJSON(w, s.gameState())
+}
+
+func (s *Server) handleCommands(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var batch swagger.DungeonsandtrollsCommandsBatch
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid commands: %v", err))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.apply(batch)
+	if err != nil {
+		errorType := swagger.ERROR__DungeonsandtrollsEventType
+		s.nextEvents = append(s.nextEvents, swagger.DungeonsandtrollsEvent{
+			Type_:    &errorType,
+			Message:  err.Error(),
+			PlayerId: characterID,
+		})
+	}
+	s.idle = false
+	s.advance()
+
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, struct{}{})
+}
+
+func (s *Server) handleRespawn(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.respawn()
+	writeJSON(w, struct{}{})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("sim: write response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(swagger.RpcStatus{
+		Code:    int32(status),
+		Message: message,
+	})
+}
@@ -0,0 +1,68 @@
+package sim_test
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+	"github.com/liennie/gdt/internal/bot"
+	"github.com/liennie/gdt/internal/runner"
+	"github.com/liennie/gdt/internal/sim"
+)
+
+func TestPlay(t *testing.T) {
+	if !testing.Verbose() {
+		log.SetOutput(io.Discard)
+		defer log.SetOutput(os.Stderr)
+	}
+
+	server := sim.New(1)
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	cfg := swagger.NewConfiguration()
+	cfg.BasePath = ts.URL
+	client := swagger.NewAPIClient(cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	ctx = context.WithValue(ctx, swagger.ContextAPIKey, swagger.APIKey{Key: "test"})
+
+	const ticks = 500
+	play := bot.Default{Skills: bot.NewSkillTracker(), Verifier: bot.NewVerifier(), Watchdog: bot.NewWatchdog(), Memory: bot.NewMemory(), ShopCache: bot.NewShopCache()}
+	r := runner.New(client, until{Default: play, ticks: ticks, cancel: cancel})
+	r.Delay = time.Millisecond
+	r.Run(ctx)
+
+	stats := server.Stats()
+	t.Logf("%+v", stats)
+	if stats.Tick < ticks {
+		t.Fatalf("played only %d ticks", stats.Tick)
+	}
+	if stats.MaxLevel < 2 {
+		t.Errorf("reached only level %d", stats.MaxLevel)
+	}
+	if stats.Kills == 0 {
+		t.Error("killed no monsters")
+	}
+}
+
+// until plays the game until the tick, keeping the strategy a bot.Respawner.
+type until struct {
+	bot.Default
+	ticks  int32
+	cancel context.CancelFunc
+}
+
+func (u until) Decide(state swagger.DungeonsandtrollsGameState) *swagger.DungeonsandtrollsCommandsBatch {
+	if state.Tick >= u.ticks {
+		u.cancel()
+		return nil
+	}
+	return u.Default.Decide(state)
+}
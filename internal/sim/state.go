@@ -0,0 +1,127 @@
+package sim
+
+import (
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+)
+
+func (s *Server) gameState() swagger.DungeonsandtrollsGameState {
+	l := s.level(s.char.level)
+	char := s.characterState()
+	pos := s.char.position
+
+	return swagger.DungeonsandtrollsGameState{
+		Map_: &swagger.DungeonsandtrollsMap{
+			Levels: []swagger.DungeonsandtrollsLevel{s.levelState(l, char)},
+		},
+		ShopItems:       s.shop,
+		Character:       &char,
+		CurrentPosition: &pos,
+		CurrentLevel:    l.number,
+		Tick:            s.tick,
+		Score:           s.char.score,
+		MaxLevel:        s.stats.MaxLevel,
+		Events:          s.events,
+	}
+}
+
+func (s *Server) characterState() swagger.DungeonsandtrollsCharacter {
+	attrs := s.char.current()
+	maxAttrs := s.char.attributes()
+
+	var equip []swagger.DungeonsandtrollsItem
+	for _, slot := range slots {
+		if item, ok := s.char.equip[slot]; ok {
+			equip = append(equip, item)
+		}
+	}
+
+	return swagger.DungeonsandtrollsCharacter{
+		Id:              characterID,
+		Name:            characterName,
+		Attributes:      &attrs,
+		MaxAttributes:   &maxAttrs,
+		Money:           s.char.money,
+		Equip:           equip,
+		Score:           s.char.score,
+		SkillPoints:     s.char.skillPoints,
+		LastDamageTaken: s.tick - s.char.damagedTick,
+		Coordinates: &swagger.DungeonsandtrollsCoordinates{
+			Level:     s.char.level,
+			PositionX: s.char.position.PositionX,
+			PositionY: s.char.position.PositionY,
+		},
+		Stun: &swagger.DungeonsandtrollsStun{},
+	}
+}
+
+func (s *Server) levelState(l *level, char swagger.DungeonsandtrollsCharacter) swagger.DungeonsandtrollsLevel {
+	objects := map[position]*swagger.DungeonsandtrollsMapObjects{}
+	object := func(pos position) *swagger.DungeonsandtrollsMapObjects {
+		if o, ok := objects[pos]; ok {
+			return o
+		}
+		p := pos
+		o := &swagger.DungeonsandtrollsMapObjects{Position: &p}
+		objects[pos] = o
+		return o
+	}
+
+	for pos := range l.walls {
+		object(pos).IsWall = true
+	}
+	for pos := range l.doors {
+		object(pos).IsDoor = true
+	}
+	object(l.spawn).IsSpawn = true
+	object(l.stairs).IsStairs = true
+	for pos, dest := range l.portals {
+		object(pos).Portal = &swagger.DungeonsandtrollsWaypoint{DestinationFloor: dest}
+	}
+	for pos, items := range l.items {
+		if len(items) > 0 {
+			object(pos).Items = append(object(pos).Items, items...)
+		}
+	}
+	for _, m := range l.monsters {
+		o := object(m.position)
+		monster := m.DungeonsandtrollsMonster
+		attrs := *m.Attributes
+		monster.Attributes = &attrs
+		monster.Stun = &swagger.DungeonsandtrollsStun{IsStunned: m.stunned > 0}
+		o.Monsters = append(o.Monsters, monster)
+	}
+	o := object(s.char.position)
+	o.Players = append(o.Players, char)
+
+	state := swagger.DungeonsandtrollsLevel{
+		Level:  l.number,
+		Width:  l.width,
+		Height: l.height,
+	}
+	for y := int32(0); y < l.height; y++ {
+		for x := int32(0); x < l.width; x++ {
+			if o, ok := objects[position{PositionX: x, PositionY: y}]; ok {
+				state.Objects = append(state.Objects, *o)
+			}
+		}
+	}
+
+	dist := l.distances(s.char.position)
+	for y := int32(0); y < l.height; y++ {
+		for x := int32(0); x < l.width; x++ {
+			pos := position{PositionX: x, PositionY: y}
+			d, ok := dist[pos]
+			if !ok {
+				continue
+			}
+			p := pos
+			state.PlayerMap = append(state.PlayerMap, swagger.DungeonsandtrollsPlayerSpecificMap{
+				Position:    &p,
+				Distance:    d,
+				LineOfSight: l.lineOfSight(s.char.position, pos),
+			})
+		}
+	}
+
+	return state
+}
@@ -0,0 +1,405 @@
+package sim
+
+import (
+	"fmt"
+
+	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
+)
+
+type position = swagger.DungeonsandtrollsPosition
+
+type level struct {
+	number   int32
+	width    int32
+	height   int32
+	walls    map[position]bool
+	doors    map[position]bool
+	spawn    position
+	stairs   position
+	portals  map[position]int32
+	monsters []*monster
+	items    map[position][]swagger.DungeonsandtrollsItem
+}
+
+type monster struct {
+	swagger.DungeonsandtrollsMonster
+
+	position position
+	stunned  int32
+}
+
+type character struct {
+	base        swagger.DungeonsandtrollsAttributes
+	life        float32
+	stamina     float32
+	mana        float32
+	money       int32
+	skillPoints float32
+	equip       map[swagger.DungeonsandtrollsItemType]swagger.DungeonsandtrollsItem
+	level       int32
+	position    position
+	damagedTick int32
+	score       float32
+	// casts is the tick each skill was last cast at.
+	casts map[string]int32
+}
+
+var slots = []swagger.DungeonsandtrollsItemType{
+	swagger.MAIN_HAND_DungeonsandtrollsItemType,
+	swagger.OFF_HAND_DungeonsandtrollsItemType,
+	swagger.HEAD_DungeonsandtrollsItemType,
+	swagger.BODY_DungeonsandtrollsItemType,
+	swagger.LEGS_DungeonsandtrollsItemType,
+	swagger.NECK_DungeonsandtrollsItemType,
+}
+
+const (
+	characterID   = "sim-character"
+	characterName = "Simmy"
+)
+
+func newCharacter() *character {
+	c := &character{
+		base: swagger.DungeonsandtrollsAttributes{
+			Strength:     5,
+			Dexterity:    5,
+			Intelligence: 5,
+			Willpower:    5,
+			Constitution: 5,
+			Life:         50,
+			Stamina:      50,
+			Mana:         50,
+		},
+		money:       1000,
+		skillPoints: 10,
+		equip:       map[swagger.DungeonsandtrollsItemType]swagger.DungeonsandtrollsItem{},
+		damagedTick: -100,
+		casts:       map[string]int32{},
+	}
+	c.refill()
+	return c
+}
+
+func (c *character) attributes() swagger.DungeonsandtrollsAttributes {
+	attrs := c.base
+	for _, item := range c.equip {
+		if item.Attributes != nil {
+			attrs = add(attrs, *item.Attributes)
+		}
+	}
+	return attrs
+}
+
+// current returns the attributes with life, stamina and mana replaced by their current values.
+func (c *character) current() swagger.DungeonsandtrollsAttributes {
+	attrs := c.attributes()
+	attrs.Life = c.life
+	attrs.Stamina = c.stamina
+	attrs.Mana = c.mana
+	return attrs
+}
+
+func (c *character) refill() {
+	attrs := c.attributes()
+	c.life = attrs.Life
+	c.stamina = attrs.Stamina
+	c.mana = attrs.Mana
+}
+
+func (c *character) clamp() {
+	attrs := c.attributes()
+	c.life = min(c.life, attrs.Life)
+	c.stamina = max(min(c.stamina, attrs.Stamina), 0)
+	c.mana = max(min(c.mana, attrs.Mana), 0)
+}
+
+func (c *character) moveTo(level int32, pos position) {
+	c.level = level
+	c.position = pos
+}
+
+func (s *Server) level(n int32) *level {
+	if l, ok := s.levels[n]; ok {
+		return l
+	}
+
+	var l *level
+	if n == 0 {
+		l = s.generateShopLevel()
+	} else {
+		l = s.generateLevel(n)
+	}
+	s.levels[n] = l
+	return l
+}
+
+func newLevel(n, width, height int32) *level {
+	l := &level{
+		number:  n,
+		width:   width,
+		height:  height,
+		walls:   map[position]bool{},
+		doors:   map[position]bool{},
+		portals: map[position]int32{},
+		items:   map[position][]swagger.DungeonsandtrollsItem{},
+	}
+	for x := int32(0); x < width; x++ {
+		l.walls[position{PositionX: x, PositionY: 0}] = true
+		l.walls[position{PositionX: x, PositionY: height - 1}] = true
+	}
+	for y := int32(0); y < height; y++ {
+		l.walls[position{PositionX: 0, PositionY: y}] = true
+		l.walls[position{PositionX: width - 1, PositionY: y}] = true
+	}
+	return l
+}
+
+func (s *Server) generateShopLevel() *level {
+	l := newLevel(0, 12, 7)
+	l.spawn = position{PositionX: 2, PositionY: 3}
+	l.stairs = position{PositionX: 9, PositionY: 3}
+	return l
+}
+
+// generateLevel builds a row of rooms separated by walls with a single door each.
+// Spawn is in the first room, stairs in the last one.
+func (s *Server) generateLevel(n int32) *level {
+	const (
+		width    = 25
+		height   = 13
+		roomSize = 6
+	)
+
+	l := newLevel(n, width, height)
+
+	for x := int32(roomSize); x < width-1; x += roomSize {
+		door := 1 + s.rnd.Int31n(height-2)
+		for y := int32(1); y < height-1; y++ {
+			pos := position{PositionX: x, PositionY: y}
+			if y == door {
+				l.doors[pos] = true
+				continue
+			}
+			l.walls[pos] = true
+		}
+	}
+
+	l.spawn = position{PositionX: 2, PositionY: 1 + s.rnd.Int31n(height-2)}
+	l.stairs = position{PositionX: width - 3, PositionY: 1 + s.rnd.Int31n(height-2)}
+
+	if n%3 == 0 {
+		l.portals[s.freeTile(l, roomSize)] = n + 2
+	}
+
+	count := min(int(n)+1, 6)
+	for i := 0; i < count; i++ {
+		l.monsters = append(l.monsters, newMonster(fmt.Sprintf("monster-%d-%d", n, i), n, s.freeTile(l, roomSize)))
+	}
+
+	return l
+}
+
+// freeTile returns a random empty tile at least minX tiles from the left edge.
+func (s *Server) freeTile(l *level, minX int32) position {
+	for {
+		pos := position{
+			PositionX: minX + s.rnd.Int31n(l.width-minX-1),
+			PositionY: 1 + s.rnd.Int31n(l.height-2),
+		}
+		if l.walkable(pos) && !l.doors[pos] && pos != l.stairs && pos != l.spawn && l.monsterAt(pos) == nil {
+			if _, ok := l.portals[pos]; !ok {
+				return pos
+			}
+		}
+	}
+}
+
+func newMonster(id string, n int32, pos position) *monster {
+	attrs := swagger.DungeonsandtrollsAttributes{
+		Strength:    2 + float32(n),
+		SlashResist: float32(n),
+		Life:        15 + 10*float32(n),
+	}
+	// Every other floor is full of fire-resistant monsters.
+	if n%2 == 0 {
+		attrs.FireResist = 5 * float32(n)
+	}
+	maxAttrs := attrs
+
+	target := swagger.CHARACTER_SkillTarget
+	damageType := swagger.SLASH_DungeonsandtrollsDamageType
+	slot := swagger.MAIN_HAND_DungeonsandtrollsItemType
+
+	return &monster{
+		DungeonsandtrollsMonster: swagger.DungeonsandtrollsMonster{
+			Id:             id,
+			Name:           "Goblin",
+			Faction:        "monster",
+			Attributes:     &attrs,
+			MaxAttributes:  &maxAttrs,
+			LifePercentage: 100,
+			Score:          10 * float32(n),
+			Algorithm:      "aggressive",
+			EquippedItems: []swagger.DungeonsandtrollsItem{{
+				Id:           id + "-claws",
+				Name:         "Claws",
+				Slot:         &slot,
+				Requirements: &swagger.DungeonsandtrollsAttributes{},
+				Attributes:   &swagger.DungeonsandtrollsAttributes{},
+				Skills: []swagger.DungeonsandtrollsSkill{{
+					Id:           id + "-claw",
+					Name:         "Claw",
+					Target:       &target,
+					Cost:         &swagger.DungeonsandtrollsAttributes{},
+					Range_:       &swagger.DungeonsandtrollsAttributes{Constant: 1},
+					DamageAmount: &swagger.DungeonsandtrollsAttributes{Strength: 1},
+					DamageType:   &damageType,
+					Flags:        &swagger.DungeonsandtrollsSkillGenericFlags{},
+				}},
+			}},
+		},
+		position: pos,
+	}
+}
+
+func (l *level) inside(pos position) bool {
+	return pos.PositionX >= 0 && pos.PositionY >= 0 && pos.PositionX < l.width && pos.PositionY < l.height
+}
+
+func (l *level) walkable(pos position) bool {
+	return l.inside(pos) && !l.walls[pos]
+}
+
+func (l *level) monsterAt(pos position) *monster {
+	for _, m := range l.monsters {
+		if m.position == pos {
+			return m
+		}
+	}
+	return nil
+}
+
+func (l *level) monsterByID(id string) *monster {
+	for _, m := range l.monsters {
+		if m.Id == id {
+			return m
+		}
+	}
+	return nil
+}
+
+func (l *level) removeMonster(target *monster) {
+	for i, m := range l.monsters {
+		if m == target {
+			l.monsters = append(l.monsters[:i], l.monsters[i+1:]...)
+			return
+		}
+	}
+}
+
+var directions = []position{
+	{PositionX: 1},
+	{PositionX: -1},
+	{PositionY: 1},
+	{PositionY: -1},
+}
+
+func step(pos, dir position) position {
+	return position{
+		PositionX: pos.PositionX + dir.PositionX,
+		PositionY: pos.PositionY + dir.PositionY,
+	}
+}
+
+// distances returns the walking distance from pos to every reachable tile.
+func (l *level) distances(from position) map[position]int32 {
+	dist := map[position]int32{from: 0}
+	queue := []position{from}
+	for len(queue) > 0 {
+		pos := queue[0]
+		queue = queue[1:]
+		for _, dir := range directions {
+			next := step(pos, dir)
+			if _, ok := dist[next]; ok || !l.walkable(next) {
+				continue
+			}
+			dist[next] = dist[pos] + 1
+			queue = append(queue, next)
+		}
+	}
+	return dist
+}
+
+// nextStep returns the first tile on a shortest path from `from` to `to`
+// avoiding tiles for which blocked returns true.
+func (l *level) nextStep(from, to position, blocked func(position) bool) (position, bool) {
+	if from == to {
+		return from, false
+	}
+
+	prev := map[position]position{from: from}
+	queue := []position{from}
+	for len(queue) > 0 {
+		pos := queue[0]
+		queue = queue[1:]
+		if pos == to {
+			for prev[pos] != from {
+				pos = prev[pos]
+			}
+			return pos, true
+		}
+		for _, dir := range directions {
+			next := step(pos, dir)
+			if _, ok := prev[next]; ok || !l.walkable(next) || (next != to && blocked(next)) {
+				continue
+			}
+			prev[next] = pos
+			queue = append(queue, next)
+		}
+	}
+	return from, false
+}
+
+// lineOfSight walks a Bresenham line between the tiles and reports whether no wall is in between.
+func (l *level) lineOfSight(a, b position) bool {
+	x0, y0 := a.PositionX, a.PositionY
+	x1, y1 := b.PositionX, b.PositionY
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := int32(1), int32(1)
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		pos := position{PositionX: x0, PositionY: y0}
+		if pos != a && pos != b && l.walls[pos] {
+			return false
+		}
+		if x0 == x1 && y0 == y1 {
+			return true
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(i int32) int32 {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
+func manhattan(a, b position) int32 {
+	return abs(a.PositionX-b.PositionX) + abs(a.PositionY-b.PositionY)
+}
@@ -2,1075 +2,194 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
-	"math"
 	"os"
-	"reflect"
-	"strconv"
-	"time"
+	"os/signal"
 
 	swagger "github.com/gdg-garage/dungeons-and-trolls-go-client"
-	"golang.org/x/exp/slices"
+	"github.com/liennie/gdt/internal/bot"
+	"github.com/liennie/gdt/internal/config"
+	"github.com/liennie/gdt/internal/record"
+	"github.com/liennie/gdt/internal/runner"
 )
 
-var preferredDamageType = swagger.FIRE_DungeonsandtrollsDamageType
-
 func main() {
-	// Read command line arguments
-	if len(os.Args) < 2 {
-		log.Fatal("USAGE: ./dungeons-and-trolls-go-bot API_KEY")
+	configPath := flag.String("config", "", "path to a JSON config file (env "+config.EnvConfig+")")
+	apiKey := flag.String("key", "", "API key (env "+config.EnvAPIKey+")")
+	baseURL := flag.String("url", "", "server base URL (env "+config.EnvBaseURL+")")
+	riskTolerance := flag.Float64("risk", 0, "damage the bot is willing to take to save a single step")
+	damageTypes := flag.Int("damage-types", 0, "number of damage types to build the loadout around")
+	profile := flag.String("profile", "", "build profile for spending attribute points (default \""+bot.DefaultProfile+"\")")
+	profileFile := flag.String("profiles", "", "JSON file with additional build profiles")
+	allocation := flag.String("allocation", "", "how to spend attribute points: profile, unlock or marginal (default \"profile\")")
+	memoryFile := flag.String("memory", "", "keep the map memory in a JSON file across runs")
+	recordPath := flag.String("record", "", "record the session to a gzip compressed JSON lines file")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "USAGE: %s [flags] [run|respawn|inspect|replay FILE]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	command := "run"
+	if flag.NArg() > 0 {
+		command = flag.Arg(0)
+	}
+	if command == "replay" && flag.NArg() != 2 || command != "replay" && flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	conf, err := config.Load(*configPath, config.Config{
+		APIKey:        *apiKey,
+		BaseURL:       *baseURL,
+		RiskTolerance: *riskTolerance,
+		DamageTypes:   *damageTypes,
+		Profile:       *profile,
+		ProfileFile:   *profileFile,
+		Allocation:    *allocation,
+		MemoryFile:    *memoryFile,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if command == "replay" {
+		replay(flag.Arg(1), conf)
+		return
 	}
-	apiKey := os.Args[1]
+	if conf.APIKey == "" {
+		log.Fatal("missing API key")
+	}
+	log.Println("Server:", conf.BaseURL)
 
 	// Initialize the HTTP client and set the base URL for the API
 	cfg := swagger.NewConfiguration()
-	// TODO: use prod path
-	cfg.BasePath = "http://10.0.1.63"
+	cfg.BasePath = conf.BaseURL
 
 	// Set the X-API-key header value
-	ctx := context.WithValue(context.Background(), swagger.ContextAPIKey, swagger.APIKey{Key: apiKey})
+	ctx := context.WithValue(context.Background(), swagger.ContextAPIKey, swagger.APIKey{Key: conf.APIKey})
 
 	// Create a new client instance
 	client := swagger.NewAPIClient(cfg)
 
-	if len(os.Args) > 2 && os.Args[2] == "respawn" {
-		respawn(ctx, client)
-		return
-	}
-
-	lastYell := ""
-	lastYellTick := int32(0)
-
-	for {
-		// Use the client to make API requests
-		gameResp, httpResp, err := client.DungeonsAndTrollsApi.DungeonsAndTrollsGame(ctx, nil)
-		if err != nil {
-			log.Printf("HTTP Response: %+v\n", httpResp)
-			log.Print(err)
-			time.Sleep(time.Second)
-			continue
-		}
-		// fmt.Println("Response:", resp)
-		fmt.Println("Next tick ...")
-		command := run(gameResp)
-		if command == nil {
-			time.Sleep(time.Second)
-			continue
-		}
-
-		if command.Yell != nil {
-			if command.Yell.Text == lastYell && gameResp.Tick < lastYellTick+10 {
-				command.Yell = nil
-			} else {
-				lastYell = command.Yell.Text
-				lastYellTick = gameResp.Tick
-			}
-		}
-
-		logStruct(reflect.ValueOf(command), "Command")
-
-		_, httpResp, err = client.DungeonsAndTrollsApi.DungeonsAndTrollsCommands(ctx, *command, nil)
+	switch command {
+	case "run":
+		strategy, err := newStrategy(conf)
 		if err != nil {
-			swaggerErr, ok := err.(swagger.GenericSwaggerError)
-			if ok {
-				log.Printf("Server error response: %s\n", swaggerErr.Body())
-			} else {
-				log.Printf("HTTP Response: %+v\n", httpResp)
-				log.Print(err)
-			}
-			time.Sleep(time.Second)
-			continue
-		}
-	}
-}
-
-func respawn(ctx context.Context, client *swagger.APIClient) {
-	log.Println("Respawning ...")
-	_, httpResp, err := client.DungeonsAndTrollsApi.DungeonsAndTrollsRespawn(ctx, struct{}{}, nil)
-	if err != nil {
-		log.Printf("HTTP Response: %+v\n", httpResp)
-		log.Print(err)
-	}
-}
-
-func logStruct(v reflect.Value, name string) {
-	if v.Type().Kind() == reflect.Pointer {
-		if !v.IsNil() {
-			logStruct(v.Elem(), name)
-		}
-		return
-	}
-
-	if v.Type().Kind() == reflect.Struct {
-		for n := 0; n < v.Type().NumField(); n++ {
-			field := v.Field(n)
-			fieldName := name + "." + v.Type().Field(n).Name
-			logStruct(field, fieldName)
-		}
-		return
-	}
-
-	if v.Type().Kind() == reflect.Slice {
-		for n := 0; n < v.Len(); n++ {
-			field := v.Index(n)
-			fieldName := name + "[" + strconv.Itoa(n) + "]"
-			logStruct(field, fieldName)
-		}
-		return
-	}
-
-	log.Printf("%s: %v", name, v.Interface())
-	return
-}
-
-func run(state swagger.DungeonsandtrollsGameState) *swagger.DungeonsandtrollsCommandsBatch {
-	log.Println("Score:", state.Score)
-	log.Println("Character.Money", state.Character.Money)
-	// logStruct(reflect.ValueOf(state.Character.Equip), "Character.Equip")
-	log.Println()
-	logStruct(reflect.ValueOf(state.Character.Attributes), "Character.Attributes")
-	log.Println()
-	log.Println("CurrentLevel:", state.CurrentLevel)
-	log.Println("CurrentPosition.PositionX:", state.CurrentPosition.PositionX)
-	log.Println("CurrentPosition.PositionY:", state.CurrentPosition.PositionY)
-
-	var mainHandItem *swagger.DungeonsandtrollsItem
-	for _, item := range state.Character.Equip {
-		if *item.Slot == swagger.MAIN_HAND_DungeonsandtrollsItemType {
-			mainHandItem = &item
-			break
-		}
-	}
-
-	if state.Character.SkillPoints > 1.5 {
-		log.Println("Spending attribute points ...")
-		return &swagger.DungeonsandtrollsCommandsBatch{
-			AssignSkillPoints: spendAttributePoints(&state),
-			Yell: &swagger.DungeonsandtrollsMessage{
-				Text: "Assigning skill points.",
-			},
-		}
-	}
-
-	if mainHandItem == nil && state.Character.Coordinates.Level == 0 {
-		log.Println("Looking for items to buy ...")
-		items := shop(&state)
-		if len(items) > 0 {
-			itemIds := make([]string, len(items))
-			for i := range items {
-				itemIds[i] = items[i].Id
-			}
-
-			return &swagger.DungeonsandtrollsCommandsBatch{
-				Buy: &swagger.DungeonsandtrollsIdentifiers{Ids: itemIds},
-				Yell: &swagger.DungeonsandtrollsMessage{
-					Text: "Buying swag.",
-				},
-			}
-		} else {
-			log.Println("ERROR: Found no item to buy!")
-			return nil
-		}
-	}
-
-	stairsCoords := findStairs(&state)
-	monster := findMonster(&state)
-
-	var attackSkill *swagger.DungeonsandtrollsSkill
-
-	maxDamage := float32(0)
-	for _, equip := range state.Character.Equip {
-		for _, equipSkill := range equip.Skills {
-			equipSkill := equipSkill
-
-			if equipSkill.DamageAmount == nil {
-				continue
-			}
-			if equipSkill.CasterEffects != nil && equipSkill.CasterEffects.Attributes != nil && equipSkill.CasterEffects.Attributes.Mana != nil && equipSkill.CasterEffects.Attributes.Mana.Mana < 0 {
-				continue
-			}
-			if *equipSkill.DamageType != preferredDamageType {
-				continue
-			}
-			if *equipSkill.Target != swagger.CHARACTER_SkillTarget {
-				continue
-			}
-
-			if haveRequiredAttirbutes(state.Character.Attributes, equipSkill.Cost) {
-				rang := float32(math.Trunc(float64(calculateAttributesValue(state.Character.Attributes, equipSkill.Range_))))
-				if monster != nil {
-					rang = min(rang, float32(distance(*state.CurrentPosition, *monster.Position)))
-				}
-				damage := calculateAttributesValue(state.Character.Attributes, equipSkill.DamageAmount) * rang
-				if damage > maxDamage {
-					maxDamage = damage
-					attackSkill = &equipSkill
-				}
-			}
-		}
-	}
-
-	if state.Character.Attributes.Life < state.Character.MaxAttributes.Life &&
-		(state.Character.Attributes.Life/state.Character.MaxAttributes.Life) < (state.Character.Attributes.Stamina/state.Character.MaxAttributes.Stamina) &&
-		(state.Character.Attributes.Life/state.Character.MaxAttributes.Life) < (state.Character.Attributes.Mana/state.Character.MaxAttributes.Stamina) &&
-		state.Character.LastDamageTaken > 2 &&
-		(monster == nil || distance(*state.CurrentPosition, *monster.Position) > 6) {
-
-		var skill *swagger.DungeonsandtrollsSkill
-
-		for _, equip := range state.Character.Equip {
-			for _, equipSkill := range equip.Skills {
-				equipSkill := equipSkill
-
-				if haveRequiredAttirbutes(state.Character.Attributes, equipSkill.Cost) &&
-					equipSkill.TargetEffects != nil &&
-					equipSkill.TargetEffects.Attributes != nil &&
-					equipSkill.TargetEffects.Attributes.Life != nil &&
-					calculateAttributesValue(state.Character.Attributes, equipSkill.TargetEffects.Attributes.Life) > 0 {
-
-					skill = &equipSkill
-					break
-				}
-			}
-		}
-		if skill != nil {
-			log.Println("Healing")
-			return &swagger.DungeonsandtrollsCommandsBatch{
-				Skill: &swagger.DungeonsandtrollsSkillUse{
-					SkillId:  skill.Id,
-					TargetId: state.Character.Id,
-				},
-				Yell: &swagger.DungeonsandtrollsMessage{
-					Text: "<color=\"green\">Healing.</color>",
-				},
-			}
-		}
-	}
-
-	if (state.Character.Attributes.Stamina < state.Character.MaxAttributes.Stamina &&
-		state.Character.LastDamageTaken > 2 &&
-		(monster == nil || attackSkill == nil || distance(*state.CurrentPosition, *monster.Position) > int(calculateAttributesValue(state.Character.Attributes, attackSkill.Range_)+1))) ||
-		(attackSkill != nil && !haveRequiredAttirbutes(state.Character.Attributes, attackSkill.Cost) && state.Character.LastDamageTaken > 2) ||
-		(state.Character.Attributes.Mana < state.Character.MaxAttributes.Mana &&
-			state.Character.LastDamageTaken > 2 &&
-			(monster == nil || attackSkill == nil || distance(*state.CurrentPosition, *monster.Position) > int(calculateAttributesValue(state.Character.Attributes, attackSkill.Range_)+1))) ||
-		(attackSkill != nil && !haveRequiredAttirbutes(state.Character.Attributes, attackSkill.Cost) && state.Character.LastDamageTaken > 2) {
-
-		var skill *swagger.DungeonsandtrollsSkill
-
-		for _, equip := range state.Character.Equip {
-			for _, equipSkill := range equip.Skills {
-				equipSkill := equipSkill
-
-				if haveRequiredAttirbutes(state.Character.Attributes, equipSkill.Cost) &&
-					!equipSkill.Flags.Passive &&
-					equipSkill.CasterEffects != nil &&
-					equipSkill.CasterEffects.Attributes != nil &&
-					equipSkill.CasterEffects.Attributes.Stamina != nil &&
-					calculateAttributesValue(state.Character.Attributes, equipSkill.CasterEffects.Attributes.Stamina) > 0 {
-
-					skill = &equipSkill
-					break
-				}
-			}
-		}
-		if skill != nil {
-			log.Println("Resting")
-			return &swagger.DungeonsandtrollsCommandsBatch{
-				Skill: &swagger.DungeonsandtrollsSkillUse{
-					SkillId: skill.Id,
-				},
-				Yell: &swagger.DungeonsandtrollsMessage{
-					Text: "<color=#00FFFF>Resting.</color>",
-				},
-			}
-		}
-	}
-
-	if monster != nil {
-		if attackSkill != nil {
-			log.Println("Let's fight!")
-			dist := mapDistance(*monster.Position, state)
-			if dist <= int(calculateAttributesValue(state.Character.Attributes, attackSkill.Range_)) && lineOfSight(*monster.Position, state) {
-				log.Println("Attacking ...")
-				log.Println("Picked skill:", attackSkill.Name, "with target type:", *attackSkill.Target)
-				damage := calculateAttributesValue(state.Character.Attributes, attackSkill.DamageAmount)
-				log.Println("Estimated damage ignoring resistances:", damage)
-
-				if *attackSkill.Target == swagger.POSITION_SkillTarget {
-					return &swagger.DungeonsandtrollsCommandsBatch{
-						Skill: &swagger.DungeonsandtrollsSkillUse{
-							SkillId:  attackSkill.Id,
-							Position: monster.Position,
-						},
-						Yell: &swagger.DungeonsandtrollsMessage{
-							Text: fmt.Sprintf("<color=\"red\">%s!</color>", attackSkill.Name),
-						},
-					}
-				}
-				if *attackSkill.Target == swagger.CHARACTER_SkillTarget {
-					return &swagger.DungeonsandtrollsCommandsBatch{
-						Skill: &swagger.DungeonsandtrollsSkillUse{
-							SkillId:  attackSkill.Id,
-							TargetId: monster.Monsters[0].Id,
-						},
-						Yell: &swagger.DungeonsandtrollsMessage{
-							Text: fmt.Sprintf("<color=\"red\">%s!</color>", attackSkill.Name),
-						},
-					}
-				}
-				return &swagger.DungeonsandtrollsCommandsBatch{
-					Skill: &swagger.DungeonsandtrollsSkillUse{
-						SkillId: attackSkill.Id,
-					},
-					Yell: &swagger.DungeonsandtrollsMessage{
-						Text: fmt.Sprintf("<color=\"red\">%s!</color>", attackSkill.Name),
-					},
-				}
-			} else {
-				return &swagger.DungeonsandtrollsCommandsBatch{
-					Move: monster.Position,
-					Yell: &swagger.DungeonsandtrollsMessage{
-						Text: "<color=\"yellow\">Let's fight!</color>",
-					},
-				}
-			}
-		} else {
-			log.Println("No skill. Moving towards stairs ...")
-			return &swagger.DungeonsandtrollsCommandsBatch{
-				Move: findSpawn(&state),
-				Yell: &swagger.DungeonsandtrollsMessage{
-					Text: "<color=\"purple\">Running away!</color>",
-				},
-			}
-		}
-	}
-
-	log.Println("No monsters. Let's find stairs ...")
-
-	if stairsCoords == nil {
-		log.Println("Can't find stairs")
-		return &swagger.DungeonsandtrollsCommandsBatch{
-			Yell: &swagger.DungeonsandtrollsMessage{
-				Text: "Where are the stairs? I can't find them!",
-			},
-		}
-	}
-
-	if distance(*state.CurrentPosition, *stairsCoords) <= 1 && state.CurrentLevel != 0 {
-		players := playersOnCurrentLevel(state)
-
-		maxDist := 0
-		var maxPlayer swagger.DungeonsandtrollsCharacter
-		for _, player := range players {
-			if player.Id == state.Character.Id {
-				continue
-			}
-
-			dist := distance(*stairsCoords, coords2pos(*player.Coordinates))
-			if dist > maxDist {
-				maxDist = dist
-				maxPlayer = player
-			}
-		}
-
-		if maxDist > 1 {
-			return &swagger.DungeonsandtrollsCommandsBatch{
-				Yell: &swagger.DungeonsandtrollsMessage{
-					Text: fmt.Sprintf("Hurry up, %s!", maxPlayer.Name),
-				},
-				Move: state.CurrentPosition,
-			}
-		}
-	}
-
-	log.Println("Moving towards stairs ...")
-	return &swagger.DungeonsandtrollsCommandsBatch{
-		Move: stairsCoords,
-		Yell: &swagger.DungeonsandtrollsMessage{
-			Text: "<color=\"yellow\">Let's go.</color>",
-		},
-	}
-}
-
-func spendAttributePoints(state *swagger.DungeonsandtrollsGameState) *swagger.DungeonsandtrollsAttributes {
-	state.Character.SkillPoints -= 0.1
-	return &swagger.DungeonsandtrollsAttributes{
-		Strength:  state.Character.SkillPoints / 6,
-		Dexterity: state.Character.SkillPoints / 6,
-		// Intelligence: state.Character.SkillPoints / 7,
-		// Willpower:    state.Character.SkillPoints / 7,
-		Constitution: state.Character.SkillPoints / 6,
-		SlashResist:  state.Character.SkillPoints / 6,
-		PierceResist: state.Character.SkillPoints / 6,
-		FireResist:   state.Character.SkillPoints / 6,
-		// PoisonResist:   state.Character.SkillPoints / 13,
-		// ElectricResist: state.Character.SkillPoints / 13,
-		// Life:           state.Character.SkillPoints / 13,
-		// Stamina:        state.Character.SkillPoints / 13,
-		// Mana:           state.Character.SkillPoints / 13,
-	}
-}
-
-func shop(state *swagger.DungeonsandtrollsGameState) []swagger.DungeonsandtrollsItem {
-	type shopItem struct {
-		Value float32
-		Items []swagger.DungeonsandtrollsItem
-	}
-
-	shop := state.ShopItems
-
-	var bestItems []shopItem
-	newBestItems := []shopItem{}
-
-	totalCost := func(items ...swagger.DungeonsandtrollsItem) int {
-		totalPrice := 0
-		for _, item := range items {
-			totalPrice += int(item.Price)
-		}
-		return totalPrice
-	}
-
-	moneyLimits := []float32{
-		float32(state.Character.Money) / 4,
-		float32(state.Character.Money) / 6,
-		float32(state.Character.Money) / 6,
-		float32(state.Character.Money) / 6,
-		float32(state.Character.Money) / 6,
-		float32(state.Character.Money) / 6,
-	}
-	damageWeight := float32(20)
-	restWeight := float32(0.02)
-	resistWeight := float32(0.05)
-	rangeWeight := float32(0.5)
-	cutoff := 5000
-
-	for _, item := range shop {
-		if float32(item.Price) <= moneyLimits[0] && totalCost(item) <= int(state.Character.Money) {
-			maxDamage := float32(0)
-
-			for _, skill := range item.Skills {
-				if skill.DamageAmount == nil {
-					continue
-				}
-				if skill.CasterEffects != nil && skill.CasterEffects.Attributes != nil && skill.CasterEffects.Attributes.Mana != nil && skill.CasterEffects.Attributes.Mana.Mana < 0 {
-					continue
-				}
-				if *skill.DamageType != preferredDamageType {
-					continue
-				}
-				if *skill.Target != swagger.CHARACTER_SkillTarget {
-					continue
-				}
-
-				damage := calculateAttributesValue(&swagger.DungeonsandtrollsAttributes{
-					Strength:       1,
-					Dexterity:      1,
-					Intelligence:   1,
-					Willpower:      1,
-					Constitution:   1,
-					SlashResist:    1,
-					PierceResist:   1,
-					FireResist:     1,
-					PoisonResist:   1,
-					ElectricResist: 1,
-					Life:           1,
-					Stamina:        1,
-					Mana:           1,
-				}, skill.DamageAmount)
-
-				if damage > maxDamage {
-					maxDamage = damage
-				}
-			}
-
-			if maxDamage > 0 {
-				for _, item2 := range shop {
-					attrs := addAttributes(
-						state.Character.Attributes,
-						item.Attributes,
-						item2.Attributes,
-					)
-
-					if float32(item2.Price) <= moneyLimits[1] &&
-						*item.Slot != *item2.Slot &&
-						totalCost(item, item2) <= int(state.Character.Money) {
-
-						skill, value := getItemDamage(&item, attrs)
-						value = 1 + value*value*value*damageWeight
-						value += float32(math.Trunc(float64(calculateAttributesValue(attrs, skill.Range_)))) * rangeWeight
-
-						newBestItems = append(newBestItems, shopItem{
-							Value: value,
-							Items: []swagger.DungeonsandtrollsItem{
-								item,
-								item2,
-							},
-						})
-					}
-				}
-			}
-		}
-	}
-
-	log.Println("First pass: ", len(newBestItems))
-
-	slices.SortFunc(newBestItems, func(a, b shopItem) int {
-		if a.Value > b.Value {
-			return -1
-		}
-		if a.Value < b.Value {
-			return 1
+			log.Fatal(err)
 		}
-		return 0
-	})
-	bestItems = newBestItems[:min(len(newBestItems), cutoff)]
-	newBestItems = newBestItems[:0]
-
-	for _, bestItem := range bestItems {
-		for _, item := range shop {
-			if float32(item.Price) <= moneyLimits[2] &&
-				*item.Slot != *bestItem.Items[0].Slot &&
-				*item.Slot != *bestItem.Items[1].Slot &&
-				totalCost(bestItem.Items[0], bestItem.Items[1], item) <= int(state.Character.Money) {
-
-				attrs := addAttributes(
-					state.Character.Attributes,
-					bestItem.Items[0].Attributes,
-					bestItem.Items[1].Attributes,
-					item.Attributes,
-				)
-
-				if !haveRequiredAttirbutes(attrs, item.Requirements) ||
-					!haveRequiredAttirbutes(attrs, bestItem.Items[0].Requirements) ||
-					!haveRequiredAttirbutes(attrs, bestItem.Items[1].Requirements) {
-
-					continue
-				}
-
-				_, aStam := getItemRest(&bestItem.Items[0], attrs)
-				_, bStam := getItemRest(&bestItem.Items[1], attrs)
-				_, cStam := getItemRest(&item, attrs)
-
-				_, aPatch := getItemPatch(&bestItem.Items[0], attrs)
-				_, bPatch := getItemPatch(&bestItem.Items[1], attrs)
-				_, cPatch := getItemPatch(&item, attrs)
-
-				if aStam > 0 || bStam > 0 || cStam > 0 {
-					skill, value := getItemDamage(&bestItem.Items[0], attrs)
-					value = 1 + value*value*value*damageWeight
-					value += float32(math.Trunc(float64(calculateAttributesValue(attrs, skill.Range_)))) * rangeWeight
-					value += (1 + max(aStam, bStam, cStam)*restWeight)
-					value += (1 + max(aPatch, bPatch, cPatch)*restWeight)
-
-					newBestItems = append(newBestItems, shopItem{
-						Value: value,
-						Items: append(bestItem.Items[0:2:2], item),
-					})
-				}
-			}
-		}
-	}
-
-	log.Println("Second pass: ", len(newBestItems))
-
-	slices.SortFunc(newBestItems, func(a, b shopItem) int {
-		if a.Value > b.Value {
-			return -1
-		}
-		if a.Value < b.Value {
-			return 1
-		}
-		return 0
-	})
-	bestItems = newBestItems[:min(len(newBestItems), cutoff)]
-	newBestItems = newBestItems[:0]
-
-	for _, bestItem := range bestItems {
-		for _, item := range shop {
-			if float32(item.Price) <= moneyLimits[3] &&
-				*item.Slot != *bestItem.Items[0].Slot &&
-				*item.Slot != *bestItem.Items[1].Slot &&
-				*item.Slot != *bestItem.Items[2].Slot &&
-				totalCost(bestItem.Items[0], bestItem.Items[1], bestItem.Items[2], item) <= int(state.Character.Money) {
-
-				attrs := addAttributes(
-					state.Character.Attributes,
-					bestItem.Items[0].Attributes,
-					bestItem.Items[1].Attributes,
-					bestItem.Items[2].Attributes,
-					item.Attributes,
-				)
-
-				if !haveRequiredAttirbutes(attrs, item.Requirements) ||
-					!haveRequiredAttirbutes(attrs, bestItem.Items[0].Requirements) ||
-					!haveRequiredAttirbutes(attrs, bestItem.Items[1].Requirements) ||
-					!haveRequiredAttirbutes(attrs, bestItem.Items[2].Requirements) {
-
-					continue
-				}
-
-				_, aStam := getItemRest(&bestItem.Items[0], attrs)
-				_, bStam := getItemRest(&bestItem.Items[1], attrs)
-				_, cStam := getItemRest(&bestItem.Items[2], attrs)
-				_, dStam := getItemRest(&item, attrs)
-
-				_, aPatch := getItemPatch(&bestItem.Items[0], attrs)
-				_, bPatch := getItemPatch(&bestItem.Items[1], attrs)
-				_, cPatch := getItemPatch(&bestItem.Items[2], attrs)
-				_, dPatch := getItemPatch(&item, attrs)
-
-				if aPatch > 0 || bPatch > 0 || cPatch > 0 || dPatch > 0 {
-					skill, value := getItemDamage(&bestItem.Items[0], attrs)
-					value = 1 + value*value*value*damageWeight
-					value += float32(math.Trunc(float64(calculateAttributesValue(attrs, skill.Range_)))) * rangeWeight
-					value += (1 + max(aStam, bStam, cStam, dStam)*restWeight)
-					value += (1 + max(aPatch, bPatch, cPatch, dPatch)*restWeight)
-					value += (1 + item.Attributes.SlashResist*0.1) * (1 + item.Attributes.PierceResist*0.75) * (1 + item.Attributes.FireResist*1) * resistWeight
-
-					newBestItems = append(newBestItems, shopItem{
-						Value: value,
-						Items: append(bestItem.Items[0:3:3], item),
-					})
-				}
-			}
-		}
-	}
-
-	log.Println("Third pass: ", len(newBestItems))
-
-	slices.SortFunc(newBestItems, func(a, b shopItem) int {
-		if a.Value > b.Value {
-			return -1
-		}
-		if a.Value < b.Value {
-			return 1
-		}
-		return 0
-	})
-	bestItems = newBestItems[:min(len(newBestItems), cutoff)]
-	newBestItems = newBestItems[:0]
-
-	for _, bestItem := range bestItems {
-		for _, item := range shop {
-			if float32(item.Price) <= moneyLimits[4] &&
-				haveRequiredAttirbutes(state.Character.Attributes, item.Requirements) &&
-				*item.Slot != *bestItem.Items[0].Slot &&
-				*item.Slot != *bestItem.Items[1].Slot &&
-				*item.Slot != *bestItem.Items[2].Slot &&
-				*item.Slot != *bestItem.Items[3].Slot &&
-				totalCost(bestItem.Items[0], bestItem.Items[1], bestItem.Items[2], bestItem.Items[3], item) <= int(state.Character.Money) {
-
-				attrs := addAttributes(
-					state.Character.Attributes,
-					bestItem.Items[0].Attributes,
-					bestItem.Items[1].Attributes,
-					bestItem.Items[2].Attributes,
-					bestItem.Items[3].Attributes,
-					item.Attributes,
-				)
-
-				if !haveRequiredAttirbutes(attrs, item.Requirements) ||
-					!haveRequiredAttirbutes(attrs, bestItem.Items[0].Requirements) ||
-					!haveRequiredAttirbutes(attrs, bestItem.Items[1].Requirements) ||
-					!haveRequiredAttirbutes(attrs, bestItem.Items[2].Requirements) ||
-					!haveRequiredAttirbutes(attrs, bestItem.Items[3].Requirements) {
-
-					continue
-				}
-
-				_, aStam := getItemRest(&bestItem.Items[0], attrs)
-				_, bStam := getItemRest(&bestItem.Items[1], attrs)
-				_, cStam := getItemRest(&bestItem.Items[2], attrs)
-				_, dStam := getItemRest(&bestItem.Items[3], attrs)
-				_, eStam := getItemRest(&item, attrs)
-
-				_, aPatch := getItemPatch(&bestItem.Items[0], attrs)
-				_, bPatch := getItemPatch(&bestItem.Items[1], attrs)
-				_, cPatch := getItemPatch(&bestItem.Items[2], attrs)
-				_, dPatch := getItemPatch(&bestItem.Items[3], attrs)
-				_, ePatch := getItemPatch(&item, attrs)
-
-				skill, value := getItemDamage(&bestItem.Items[0], attrs)
-				value = 1 + value*value*value*damageWeight
-				value += float32(math.Trunc(float64(calculateAttributesValue(attrs, skill.Range_)))) * rangeWeight
-				value += (1 + max(aStam, bStam, cStam, dStam, eStam)*restWeight)
-				value += (1 + max(aPatch, bPatch, cPatch, dPatch, ePatch)*restWeight)
-				value += (1 + bestItem.Items[3].Attributes.SlashResist*0.1) * (1 + bestItem.Items[3].Attributes.PierceResist*0.75) * (1 + bestItem.Items[3].Attributes.FireResist*1) * resistWeight
-				value += (1 + item.Attributes.SlashResist*0.1) * (1 + item.Attributes.PierceResist*0.75) * (1 + item.Attributes.FireResist*1) * resistWeight
-
-				newBestItems = append(newBestItems, shopItem{
-					Value: value,
-					Items: append(bestItem.Items[0:4:4], item),
-				})
+		r := runner.New(client, strategy)
+		if *recordPath != "" {
+			recorder, err := record.Create(*recordPath)
+			if err != nil {
+				log.Fatal(err)
 			}
-		}
-	}
-
-	log.Println("Fourth pass: ", len(newBestItems))
-
-	slices.SortFunc(newBestItems, func(a, b shopItem) int {
-		if a.Value > b.Value {
-			return -1
-		}
-		if a.Value < b.Value {
-			return 1
-		}
-		return 0
-	})
-	bestItems = newBestItems[:min(len(newBestItems), cutoff)]
-	newBestItems = newBestItems[:0]
-
-	for _, bestItem := range bestItems {
-		for _, item := range shop {
-			if float32(item.Price) <= moneyLimits[5] &&
-				haveRequiredAttirbutes(state.Character.Attributes, item.Requirements) &&
-				*item.Slot != *bestItem.Items[0].Slot &&
-				*item.Slot != *bestItem.Items[1].Slot &&
-				*item.Slot != *bestItem.Items[2].Slot &&
-				*item.Slot != *bestItem.Items[3].Slot &&
-				*item.Slot != *bestItem.Items[4].Slot &&
-				totalCost(bestItem.Items[0], bestItem.Items[1], bestItem.Items[2], bestItem.Items[3], bestItem.Items[4], item) <= int(state.Character.Money) {
-
-				attrs := addAttributes(
-					state.Character.Attributes,
-					bestItem.Items[0].Attributes,
-					bestItem.Items[1].Attributes,
-					bestItem.Items[2].Attributes,
-					bestItem.Items[3].Attributes,
-					bestItem.Items[4].Attributes,
-					item.Attributes,
-				)
-
-				if !haveRequiredAttirbutes(attrs, item.Requirements) ||
-					!haveRequiredAttirbutes(attrs, bestItem.Items[0].Requirements) ||
-					!haveRequiredAttirbutes(attrs, bestItem.Items[1].Requirements) ||
-					!haveRequiredAttirbutes(attrs, bestItem.Items[2].Requirements) ||
-					!haveRequiredAttirbutes(attrs, bestItem.Items[3].Requirements) ||
-					!haveRequiredAttirbutes(attrs, bestItem.Items[4].Requirements) {
-
-					continue
-				}
-
-				_, aStam := getItemRest(&bestItem.Items[0], attrs)
-				_, bStam := getItemRest(&bestItem.Items[1], attrs)
-				_, cStam := getItemRest(&bestItem.Items[2], attrs)
-				_, dStam := getItemRest(&bestItem.Items[3], attrs)
-				_, eStam := getItemRest(&bestItem.Items[4], attrs)
-				_, fStam := getItemRest(&item, attrs)
-
-				_, aPatch := getItemPatch(&bestItem.Items[0], attrs)
-				_, bPatch := getItemPatch(&bestItem.Items[1], attrs)
-				_, cPatch := getItemPatch(&bestItem.Items[2], attrs)
-				_, dPatch := getItemPatch(&bestItem.Items[3], attrs)
-				_, ePatch := getItemPatch(&bestItem.Items[4], attrs)
-				_, fPatch := getItemPatch(&item, attrs)
-
-				skill, value := getItemDamage(&bestItem.Items[0], attrs)
-				value = 1 + value*value*value*damageWeight
-				value += float32(math.Trunc(float64(calculateAttributesValue(attrs, skill.Range_)))) * rangeWeight
-				value += (1 + max(aStam, bStam, cStam, dStam, eStam, fStam)*restWeight)
-				value += (1 + max(aPatch, bPatch, cPatch, dPatch, ePatch, fPatch)*restWeight)
-				value += (1 + bestItem.Items[3].Attributes.SlashResist*0.1) * (1 + bestItem.Items[3].Attributes.PierceResist*0.75) * (1 + bestItem.Items[3].Attributes.FireResist*1) * resistWeight
-				value += (1 + bestItem.Items[4].Attributes.SlashResist*0.1) * (1 + bestItem.Items[4].Attributes.PierceResist*0.75) * (1 + bestItem.Items[4].Attributes.FireResist*1) * resistWeight
-
-				newBestItems = append(newBestItems, shopItem{
-					Value: value,
-					Items: append(bestItem.Items[0:5:5], item),
-				})
+			defer recorder.Close()
+			// Replay starts from the memory loaded now, not the one saved after the run.
+			if err := recorder.Record(record.Entry{Memory: strategy.Memory}); err != nil {
+				log.Fatal(err)
 			}
+			r.Recorder = recorder
 		}
-	}
-
-	log.Println("Fifth pass: ", len(newBestItems))
-
-	slices.SortFunc(newBestItems, func(a, b shopItem) int {
-		if a.Value > b.Value {
-			return -1
-		}
-		if a.Value < b.Value {
-			return 1
-		}
-		return 0
-	})
-	bestItems = newBestItems
-	// newBestItems = newBestItems[:0]
-
-	for _, setup := range bestItems {
-		if totalCost() > int(state.Character.Money) {
-			continue
-		}
-
-		return setup.Items
-	}
-
-	return nil
-}
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+		defer stop()
+		r.Run(ctx)
 
-func findMonster(state *swagger.DungeonsandtrollsGameState) *swagger.DungeonsandtrollsMapObjects {
-	level := state.CurrentLevel
-	for _, map_ := range state.Map_.Levels {
-		if map_.Level != level {
-			continue
-		}
-		closestDist := math.MaxInt
-		var closest *swagger.DungeonsandtrollsMapObjects
-		for i := range map_.Objects {
-			object := map_.Objects[i]
-			if len(object.Monsters) > 0 {
-				for _, monster := range object.Monsters {
-					if mapDistance(*object.Position, *state) < closestDist && monster.Faction != "neutral" {
-						log.Printf("Found monster on position: %+v\n", object.Position)
-						closestDist = distance(*state.CurrentPosition, *object.Position)
-						closest = &object
-					}
-				}
+		if conf.MemoryFile != "" {
+			if err := strategy.Memory.Save(conf.MemoryFile); err != nil {
+				log.Fatal(err)
 			}
 		}
-		return closest
+	case "respawn":
+		runner.New(client, nil).Respawn(ctx)
+	case "inspect":
+		inspect(ctx, client)
+	default:
+		log.Printf("Unknown command %q", command)
+		flag.Usage()
+		os.Exit(2)
 	}
-	return nil
 }
 
-func findStairs(state *swagger.DungeonsandtrollsGameState) *swagger.DungeonsandtrollsPosition {
-	level := state.CurrentLevel
-	for _, map_ := range state.Map_.Levels {
-		if map_.Level != level {
-			continue
-		}
-		maxPortal := 0
-		var portalPos swagger.DungeonsandtrollsPosition
-		for i := range map_.Objects {
-			object := map_.Objects[i]
-			if object.Portal != nil && object.Portal.DestinationFloor > int32(maxPortal) {
-				maxPortal = int(object.Portal.DestinationFloor)
-				portalPos = *object.Position
-			} else if object.IsStairs && state.CurrentLevel+1 > int32(maxPortal) {
-				maxPortal = int(state.CurrentLevel) + 1
-				portalPos = *object.Position
-			}
-		}
-		if maxPortal > 0 {
-			log.Printf("Found portal on position: %+v\n", portalPos)
-			return &portalPos
-		}
+// newStrategy builds the strategy played by run and replay from the configuration.
+func newStrategy(conf config.Config) (bot.Default, error) {
+	profiles, err := bot.LoadProfiles(conf.ProfileFile)
+	if err != nil {
+		return bot.Default{}, err
 	}
-	return nil
-}
-
-func findSpawn(state *swagger.DungeonsandtrollsGameState) *swagger.DungeonsandtrollsPosition {
-	for _, map_ := range state.Map_.Levels {
-		if map_.Level != state.CurrentLevel {
-			continue
-		}
-
-		for i := range map_.Objects {
-			object := map_.Objects[i]
-			if object.IsSpawn {
-				log.Printf("Found spawn on position: %+v\n", object.Position)
-				return object.Position
-			}
-		}
+	if conf.Profile == "" {
+		conf.Profile = bot.DefaultProfile
 	}
-	return nil
-}
-
-func getItemDamage(item *swagger.DungeonsandtrollsItem, attrs *swagger.DungeonsandtrollsAttributes) (*swagger.DungeonsandtrollsSkill, float32) {
-	var best *swagger.DungeonsandtrollsSkill
-	bestValue := float32(0)
-
-	for _, skill := range item.Skills {
-		skill := skill
-
-		if skill.DamageAmount == nil {
-			continue
-		}
-		if skill.CasterEffects != nil && skill.CasterEffects.Attributes != nil && skill.CasterEffects.Attributes.Mana != nil && skill.CasterEffects.Attributes.Mana.Mana < 0 {
-			continue
-		}
-		if *skill.DamageType != preferredDamageType {
-			continue
-		}
-		if *skill.Target != swagger.CHARACTER_SkillTarget {
-			continue
-		}
-
-		value := calculateAttributesValue(skill.DamageAmount, attrs)
-		if value > bestValue {
-			bestValue = value
-			best = &skill
-		}
+	profile, ok := profiles[conf.Profile]
+	if !ok {
+		return bot.Default{}, fmt.Errorf("unknown profile %q", conf.Profile)
 	}
-
-	return best, bestValue
-}
-
-func getItemRest(item *swagger.DungeonsandtrollsItem, attrs *swagger.DungeonsandtrollsAttributes) (*swagger.DungeonsandtrollsSkill, float32) {
-	var best *swagger.DungeonsandtrollsSkill
-	bestValue := float32(0)
-
-	for _, skill := range item.Skills {
-		skill := skill
-
-		if !skill.Flags.Passive && skill.CasterEffects != nil && skill.CasterEffects.Attributes != nil && skill.CasterEffects.Attributes.Stamina != nil && skill.CasterEffects.Attributes.Mana != nil {
-			stam := calculateAttributesValue(skill.CasterEffects.Attributes.Stamina, attrs)
-			mana := calculateAttributesValue(skill.CasterEffects.Attributes.Mana, attrs)
-			value := stam * mana * mana
-			value *= value
-			if value > bestValue {
-				bestValue = value
-				best = &skill
-			}
-		}
+	switch bot.Allocation(conf.Allocation) {
+	case "", bot.AllocateProfile, bot.AllocateUnlock, bot.AllocateMarginal:
+	default:
+		return bot.Default{}, fmt.Errorf("unknown allocation %q", conf.Allocation)
 	}
 
-	return best, bestValue
-}
-
-func getItemPatch(item *swagger.DungeonsandtrollsItem, attrs *swagger.DungeonsandtrollsAttributes) (*swagger.DungeonsandtrollsSkill, float32) {
-	var best *swagger.DungeonsandtrollsSkill
-	bestValue := float32(0)
-
-	for _, skill := range item.Skills {
-		skill := skill
-
-		if skill.TargetEffects != nil && skill.TargetEffects.Attributes != nil && skill.TargetEffects.Attributes.Life != nil {
-			value := calculateAttributesValue(skill.TargetEffects.Attributes.Life, attrs)
-			if value > bestValue {
-				bestValue = value
-				best = &skill
-			}
+	memory := bot.NewMemory()
+	if conf.MemoryFile != "" {
+		memory, err = bot.LoadMemory(conf.MemoryFile)
+		if err != nil {
+			return bot.Default{}, err
 		}
 	}
 
-	return best, bestValue
-}
-
-func calculateAttributesValue(myAttrs *swagger.DungeonsandtrollsAttributes, attrs *swagger.DungeonsandtrollsAttributes) float32 {
-	var value float32
-	value += myAttrs.Strength * attrs.Strength
-	value += myAttrs.Dexterity * attrs.Dexterity
-	value += myAttrs.Intelligence * attrs.Intelligence
-	value += myAttrs.Willpower * attrs.Willpower
-	value += myAttrs.Constitution * attrs.Constitution
-	value += myAttrs.SlashResist * attrs.SlashResist
-	value += myAttrs.PierceResist * attrs.PierceResist
-	value += myAttrs.FireResist * attrs.FireResist
-	value += myAttrs.PoisonResist * attrs.PoisonResist
-	value += myAttrs.ElectricResist * attrs.ElectricResist
-	value += myAttrs.Life * attrs.Life
-	value += myAttrs.Stamina * attrs.Stamina
-	value += myAttrs.Mana * attrs.Mana
-	value += attrs.Constant
-	return value
-}
-
-func haveRequiredAttirbutes(myAttrs *swagger.DungeonsandtrollsAttributes, requirements *swagger.DungeonsandtrollsAttributes) bool {
-	return myAttrs.Strength >= requirements.Strength &&
-		myAttrs.Dexterity >= requirements.Dexterity &&
-		myAttrs.Intelligence >= requirements.Intelligence &&
-		myAttrs.Willpower >= requirements.Willpower &&
-		myAttrs.Constitution >= requirements.Constitution &&
-		myAttrs.SlashResist >= requirements.SlashResist &&
-		myAttrs.PierceResist >= requirements.PierceResist &&
-		myAttrs.FireResist >= requirements.FireResist &&
-		myAttrs.PoisonResist >= requirements.PoisonResist &&
-		myAttrs.ElectricResist >= requirements.ElectricResist &&
-		myAttrs.Life >= requirements.Life &&
-		myAttrs.Stamina >= requirements.Stamina &&
-		myAttrs.Mana >= requirements.Mana
+	return bot.Default{
+		RiskTolerance: conf.RiskTolerance,
+		DamageTypes:   conf.DamageTypes,
+		Profile:       profile,
+		Allocation:    bot.Allocation(conf.Allocation),
+		Skills:        bot.NewSkillTracker(),
+		Verifier:      bot.NewVerifier(),
+		Watchdog:      bot.NewWatchdog(),
+		Memory:        memory,
+		ShopCache:     bot.NewShopCache(),
+	}, nil
 }
 
-func addAttributes(attrs ...*swagger.DungeonsandtrollsAttributes) *swagger.DungeonsandtrollsAttributes {
-	if len(attrs) == 0 {
-		return nil
-	}
-	if len(attrs) == 1 {
-		return attrs[0]
-	}
-
-	firstAttrs := attrs[0]
-	otherAttrs := addAttributes(attrs[1:]...)
-
-	return &swagger.DungeonsandtrollsAttributes{
-		Strength:       firstAttrs.Strength + otherAttrs.Strength,
-		Dexterity:      firstAttrs.Dexterity + otherAttrs.Dexterity,
-		Intelligence:   firstAttrs.Intelligence + otherAttrs.Intelligence,
-		Willpower:      firstAttrs.Willpower + otherAttrs.Willpower,
-		Constitution:   firstAttrs.Constitution + otherAttrs.Constitution,
-		SlashResist:    firstAttrs.SlashResist + otherAttrs.SlashResist,
-		PierceResist:   firstAttrs.PierceResist + otherAttrs.PierceResist,
-		FireResist:     firstAttrs.FireResist + otherAttrs.FireResist,
-		PoisonResist:   firstAttrs.PoisonResist + otherAttrs.PoisonResist,
-		ElectricResist: firstAttrs.ElectricResist + otherAttrs.ElectricResist,
-		Life:           firstAttrs.Life + otherAttrs.Life,
-		Stamina:        firstAttrs.Stamina + otherAttrs.Stamina,
-		Mana:           firstAttrs.Mana + otherAttrs.Mana,
-		Constant:       firstAttrs.Constant + otherAttrs.Constant,
+func inspect(ctx context.Context, client *swagger.APIClient) {
+	gameResp, httpResp, err := client.DungeonsAndTrollsApi.DungeonsAndTrollsGame(ctx, nil)
+	if err != nil {
+		log.Printf("HTTP Response: %+v\n", httpResp)
+		log.Fatal(err)
 	}
-}
 
-func abs(i int) int {
-	if i < 0 {
-		return -i
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(gameResp); err != nil {
+		log.Fatal(err)
 	}
-	return i
-}
-
-func distance(a, b swagger.DungeonsandtrollsPosition) int {
-	return abs(int(a.PositionX)-int(b.PositionX)) + abs(int(a.PositionY)-int(b.PositionY))
 }
 
-func lineOfSight(position swagger.DungeonsandtrollsPosition, state swagger.DungeonsandtrollsGameState) bool {
-	for _, level := range state.Map_.Levels {
-		if level.Level != state.CurrentLevel {
-			continue
-		}
-
-		for _, pm := range level.PlayerMap {
-			if *pm.Position == position {
-				return pm.LineOfSight
-			}
-		}
+func replay(path string, conf config.Config) {
+	strategy, err := newStrategy(conf)
+	if err != nil {
+		log.Fatal(err)
 	}
-	return false
-}
-
-func mapDistance(position swagger.DungeonsandtrollsPosition, state swagger.DungeonsandtrollsGameState) int {
-	for _, level := range state.Map_.Levels {
-		if level.Level != state.CurrentLevel {
-			continue
-		}
 
-		for _, pm := range level.PlayerMap {
-			if *pm.Position == position {
-				return int(pm.Distance)
-			}
-		}
+	reader, err := record.Open(path)
+	if err != nil {
+		log.Fatal(err)
 	}
-	return math.MaxInt
-}
+	defer reader.Close()
 
-func coords2pos(coords swagger.DungeonsandtrollsCoordinates) swagger.DungeonsandtrollsPosition {
-	return swagger.DungeonsandtrollsPosition{
-		PositionX: coords.PositionX,
-		PositionY: coords.PositionY,
+	summary, err := record.Replay(reader, strategy, func(diff record.Diff) {
+		recorded, _ := json.Marshal(diff.Recorded)
+		replayed, _ := json.Marshal(diff.Replayed)
+		fmt.Printf("tick %d: %v differ\n  recorded: %s\n  replayed: %s\n", diff.Tick, diff.Fields, recorded, replayed)
+	})
+	if err != nil {
+		log.Fatal(err)
 	}
-}
-
-func playersOnCurrentLevel(state swagger.DungeonsandtrollsGameState) []swagger.DungeonsandtrollsCharacter {
-	res := []swagger.DungeonsandtrollsCharacter{}
 
-	for _, level := range state.Map_.Levels {
-		if level.Level != state.CurrentLevel {
-			continue
-		}
-
-		for _, object := range level.Objects {
-			res = append(res, object.Players...)
-		}
+	fmt.Printf("%d of %d ticks differ\n", summary.Diffs, summary.Ticks)
+	if summary.Diffs > 0 {
+		os.Exit(1)
 	}
-
-	return res
 }